@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/domovonok/url-shortener/internal/auth"
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/database"
+	"github.com/domovonok/url-shortener/internal/logger"
+	apikeyRepo "github.com/domovonok/url-shortener/internal/repo/apikey"
+)
+
+// Issues a new API key for an owner. The key is printed once and only its
+// hash is stored.
+func main() {
+	ownerID := flag.Int64("owner", 0, "owner id the key belongs to")
+	name := flag.String("name", "", "human readable key name")
+	flag.Parse()
+
+	cfg := config.Load()
+	log := logger.MustInit(cfg.Debug)
+
+	if *ownerID <= 0 {
+		log.Fatal("Owner id must be positive")
+	}
+
+	dbPool := database.MustInit(cfg.DB, log)
+	defer dbPool.Close()
+
+	key, err := auth.GenerateKey()
+	if err != nil {
+		log.Fatal("Unable to generate API key", logger.Error(err))
+	}
+
+	id, err := apikeyRepo.New(dbPool).Create(context.Background(), *ownerID, *name, auth.HashKey(key))
+	if err != nil {
+		log.Fatal("Unable to store API key", logger.Error(err))
+	}
+
+	log.Info("API key created", logger.Any("id", id), logger.Any("owner_id", *ownerID))
+	fmt.Println(key)
+}
@@ -10,17 +10,30 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/domovonok/url-shortener/internal/auth"
+	"github.com/domovonok/url-shortener/internal/blocklist"
 	"github.com/domovonok/url-shortener/internal/cache"
+	"github.com/domovonok/url-shortener/internal/clientip"
 	"github.com/domovonok/url-shortener/internal/config"
 	"github.com/domovonok/url-shortener/internal/database"
+	"github.com/domovonok/url-shortener/internal/destination"
+	"github.com/domovonok/url-shortener/internal/ipfilter"
 	"github.com/domovonok/url-shortener/internal/limiter"
 	"github.com/domovonok/url-shortener/internal/logger"
 	"github.com/domovonok/url-shortener/internal/metrics"
+	apikeyRepo "github.com/domovonok/url-shortener/internal/repo/apikey"
+	clickRepo "github.com/domovonok/url-shortener/internal/repo/click"
 	linkRepo "github.com/domovonok/url-shortener/internal/repo/link"
+	"github.com/domovonok/url-shortener/internal/repo/link/codec"
 	"github.com/domovonok/url-shortener/internal/router"
+	"github.com/domovonok/url-shortener/internal/tracing"
+	"github.com/domovonok/url-shortener/internal/tracker"
 	linkHandler "github.com/domovonok/url-shortener/internal/transport/http/link"
 	linkCreateUsecase "github.com/domovonok/url-shortener/internal/usecase/link/create"
 	linkGetUsecase "github.com/domovonok/url-shortener/internal/usecase/link/get"
+	linkDeleteUsecase "github.com/domovonok/url-shortener/internal/usecase/link/remove"
+	linkStatsUsecase "github.com/domovonok/url-shortener/internal/usecase/link/stats"
+	linkUpdateUsecase "github.com/domovonok/url-shortener/internal/usecase/link/update"
 )
 
 func main() {
@@ -33,34 +46,119 @@ func main() {
 		}
 	}()
 
+	// Short URLs pointing at localhost are only useful while developing.
+	if cfg.Server.PublicBaseURL == "" {
+		if !cfg.Debug {
+			log.Fatal("PUBLIC_BASE_URL must be set unless DEBUG is enabled")
+		}
+		cfg.Server.PublicBaseURL = "http://localhost:" + cfg.Server.Port
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatal("Unable to initialize tracing", logger.Error(err))
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.GracefulShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			log.Error("Unable to flush traces", logger.Error(err))
+		}
+	}()
+
 	dbPool := database.MustInit(cfg.DB, log)
 	defer dbPool.Close()
-	repo := linkRepo.New(dbPool)
 
-	dbCache := cache.MustInit(cfg.Cache, log)
+	linkCodec, err := codec.New(cfg.Codec)
+	if err != nil {
+		log.Fatal("Invalid link codec config", logger.Error(err))
+	}
+	repo := linkRepo.New(dbPool, linkCodec)
+
+	prom := metrics.NewPrometheusMetrics()
+	metrics.StartSystemMetricsCollector(ctx, prom, cfg.MetricsPeriod)
+
+	dbCache, cacheErr := cache.Init(cfg.Cache, log)
 	defer func() {
 		if err := dbCache.Close(); err != nil {
 			log.Error("Unable to close cache", logger.Error(err))
 		}
 	}()
 
-	cacheRepo := linkRepo.NewCached(repo, dbCache, log)
+	cacheBreaker := cache.NewBreaker(dbCache, cfg.Cache.Breaker, prom, log)
+	if cacheErr != nil {
+		log.Warn("Redis is unavailable, starting without cache", logger.Error(cacheErr))
+		cacheBreaker.Trip()
+	}
+	go cacheBreaker.Run(ctx)
 
-	rateLimiter := limiter.NewTokenBucket(cfg.RateLimit)
+	cacheRepo := linkRepo.NewCached(repo, cacheBreaker, cfg.Cache, prom, log)
+	if cfg.Cache.Local.Enabled {
+		localCache := cache.NewMemory(cfg.Cache.Local, linkRepo.CachedSize, prom)
+		invalidator := cache.NewInvalidator(dbCache, localCache, cfg.Cache.Local, prom, log)
+		go invalidator.Run(ctx)
+		cacheRepo.UseLocal(localCache, invalidator)
+	}
 
-	prom := metrics.NewPrometheusMetrics()
-	metrics.StartSystemMetricsCollector(ctx, prom, cfg.MetricsPeriod)
+	rateLimiters := make(map[string]router.RateLimiter, len(cfg.RateLimit.Policies))
+	for name, policy := range cfg.RateLimit.Policies {
+		switch cfg.RateLimit.Backend {
+		case limiter.BackendLocal:
+			rateLimiters[name] = limiter.NewKeyed(policy, cfg.RateLimit.IdleTimeout)
+		case limiter.BackendRedis:
+			rateLimiters[name] = limiter.NewRedis(dbCache.Client(), name, policy, cfg.RateLimit, log)
+		default:
+			log.Fatal("Unknown rate limit backend", logger.Any("backend", cfg.RateLimit.Backend))
+		}
+	}
+
+	clicks := clickRepo.New(dbPool)
+	clickTracker := tracker.New(clicks, cfg.Clicks, prom, log)
+	go clickTracker.Run()
+
+	authenticator := auth.New(apikeyRepo.New(dbPool))
+
+	ipResolver, err := clientip.New(cfg.Server.TrustedProxies, cfg.Server.TrustedProxyHeader)
+	if err != nil {
+		log.Fatal("Invalid trusted proxies config", logger.Error(err))
+	}
+
+	ipFilter, err := ipfilter.New(cfg.IPFilter, log)
+	if err != nil {
+		log.Fatal("Invalid IP filter config", logger.Error(err))
+	}
+	go ipFilter.Run(ctx)
+
+	destinationPolicy, err := destination.NewPolicy(cfg.Destination, cfg.Server.PublicBaseURL, net.DefaultResolver)
+	if err != nil {
+		log.Fatal("Invalid destination policy config", logger.Error(err))
+	}
+
+	destinationBlocklist, err := blocklist.New(cfg.Blocklist, log, prom)
+	if err != nil {
+		log.Fatal("Unable to load blocklist", logger.Error(err))
+	}
+	go destinationBlocklist.Run(ctx)
 
 	startServer(
 		ctx,
 		linkHandler.New(
-			linkCreateUsecase.New(cacheRepo),
-			linkGetUsecase.New(cacheRepo),
+			linkCreateUsecase.New(cacheRepo, destinationPolicy, destinationBlocklist),
+			linkGetUsecase.New(cacheRepo, destinationBlocklist),
+			linkUpdateUsecase.New(cacheRepo, destinationPolicy, destinationBlocklist),
+			linkDeleteUsecase.New(cacheRepo),
+			linkStatsUsecase.New(cacheRepo, clicks),
+			clickTracker,
+			cfg.Server.PublicBaseURL,
 			log),
-		rateLimiter,
+		authenticator,
+		ipResolver,
+		ipFilter,
+		rateLimiters,
+		clickTracker,
 		prom,
 		cfg.Server,
 		log,
@@ -70,14 +168,18 @@ func main() {
 func startServer(
 	ctx context.Context,
 	linkHandler router.LinkHandler,
-	rateLimiter router.TokenBucket,
+	authenticator router.Authenticator,
+	ipResolver router.IPResolver,
+	ipFilter router.IPFilter,
+	rateLimiters map[string]router.RateLimiter,
+	clickTracker *tracker.Tracker,
 	prom *metrics.PrometheusMetrics,
 	cfg config.ServerConfig,
 	log logger.Logger,
 ) {
 	mainSrv := &http.Server{
 		Addr:    net.JoinHostPort("", cfg.Port),
-		Handler: router.New(linkHandler, rateLimiter, log, prom),
+		Handler: router.New(linkHandler, authenticator, ipResolver, ipFilter, rateLimiters, log, prom),
 	}
 
 	serverErr := make(chan error, 1)
@@ -100,12 +202,19 @@ func startServer(
 	}()
 	log.Info("Pprof server listening on", logger.Any("addr", pprofSrv.Addr))
 
-	waitGracefulShutdown(ctx, mainSrv, pprofSrv, serverErr, cfg.GracefulShutdownTimeout, log)
+	waitGracefulShutdown(ctx, mainSrv, pprofSrv, clickTracker, serverErr, cfg.GracefulShutdownTimeout, log)
 
 	log.Info("Service stopped successfully")
 }
 
-func waitGracefulShutdown(ctx context.Context, mainSrv, pprofSrv *http.Server, serverErr <-chan error, timeout time.Duration, log logger.Logger) {
+func waitGracefulShutdown(
+	ctx context.Context,
+	mainSrv, pprofSrv *http.Server,
+	clickTracker *tracker.Tracker,
+	serverErr <-chan error,
+	timeout time.Duration,
+	log logger.Logger,
+) {
 	var reason string
 	select {
 	case <-ctx.Done():
@@ -137,4 +246,12 @@ func waitGracefulShutdown(ctx context.Context, mainSrv, pprofSrv *http.Server, s
 	})
 
 	wg.Wait()
+
+	// Flushed only after the HTTP server has stopped so that no more clicks
+	// are being tracked.
+	if err := clickTracker.Close(shutdownCtx); err != nil {
+		log.Error("Click tracker flush failed", logger.Error(err))
+	} else {
+		log.Info("Click tracker flushed")
+	}
 }
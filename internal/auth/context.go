@@ -0,0 +1,14 @@
+package auth
+
+import "context"
+
+type ownerKey struct{}
+
+func WithOwner(ctx context.Context, ownerID int64) context.Context {
+	return context.WithValue(ctx, ownerKey{}, ownerID)
+}
+
+func OwnerFromContext(ctx context.Context) (int64, bool) {
+	ownerID, ok := ctx.Value(ownerKey{}).(int64)
+	return ownerID, ok
+}
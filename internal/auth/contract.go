@@ -0,0 +1,7 @@
+package auth
+
+import "context"
+
+type keyRepo interface {
+	OwnerByHash(ctx context.Context, hash []byte) (int64, error)
+}
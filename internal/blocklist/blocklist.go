@@ -0,0 +1,242 @@
+package blocklist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/destination"
+	"github.com/domovonok/url-shortener/internal/domainset"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+)
+
+// Hosts files map these names to the local machine; they are not threats.
+var localNames = map[string]struct{}{
+	"localhost":             {},
+	"localhost.localdomain": {},
+	"local":                 {},
+	"broadcasthost":         {},
+	"ip6-localhost":         {},
+	"ip6-loopback":          {},
+}
+
+type entries struct {
+	domains domainset.Set
+	urls    map[string]struct{}
+}
+
+func (e *entries) len() int {
+	return len(e.domains) + len(e.urls)
+}
+
+// Blocklist screens destinations against malicious domains, which also cover
+// their subdomains, and individual URLs loaded from a file. The file is
+// reloaded when it changes and on SIGHUP.
+type Blocklist struct {
+	path       string
+	interval   time.Duration
+	sum        [sha256.Size]byte
+	unreadable bool
+	entries    atomic.Pointer[entries]
+	log        logger.Logger
+	metrics    *metrics.PrometheusMetrics
+}
+
+func New(cfg config.BlocklistConfig, log logger.Logger, m *metrics.PrometheusMetrics) (*Blocklist, error) {
+	b := &Blocklist{
+		path:     cfg.File,
+		interval: cfg.ReloadInterval,
+		log:      log,
+		metrics:  m,
+	}
+	b.entries.Store(&entries{domains: domainset.Set{}, urls: map[string]struct{}{}})
+
+	if b.path != "" {
+		if _, err := b.reload(true); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// Blocked reports whether a destination in the canonical form produced by
+// destination.Normalize is on the blocklist. Every hit is logged and counted
+// under the stage the caller screens it at.
+func (b *Blocklist) Blocked(ctx context.Context, stage, destination string) bool {
+	rule, ok := b.match(destination)
+	if !ok {
+		return false
+	}
+
+	b.metrics.BlocklistHitsTotal.WithLabelValues(stage).Inc()
+	b.log.WithContext(ctx).Warn("Blocklisted destination",
+		logger.Any("stage", stage),
+		logger.Any("url", destination),
+		logger.Any("rule", rule),
+	)
+	return true
+}
+
+// match looks the destination up by host, then as a URL without its fragment
+// and finally without its query.
+func (b *Blocklist) match(raw string) (string, bool) {
+	e := b.entries.Load()
+	if e.len() == 0 {
+		return "", false
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	if rule, ok := e.domains.Match(u.Hostname()); ok {
+		return rule, true
+	}
+
+	u.Fragment, u.RawFragment = "", ""
+	if _, ok := e.urls[u.String()]; ok {
+		return u.String(), true
+	}
+	u.RawQuery, u.ForceQuery = "", false
+	if _, ok := e.urls[u.String()]; ok {
+		return u.String(), true
+	}
+	return "", false
+}
+
+// Run reloads the blocklist file on change or on SIGHUP until ctx is
+// cancelled. A file that fails to load leaves the previous entries in place.
+func (b *Blocklist) Run(ctx context.Context) {
+	if b.path == "" {
+		return
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.reloadAndLog(false)
+		case <-hup:
+			b.reloadAndLog(true)
+		}
+	}
+}
+
+func (b *Blocklist) reloadAndLog(force bool) {
+	reloaded, err := b.reload(force)
+	if err != nil {
+		b.log.Error("Unable to reload blocklist", logger.Any("file", b.path), logger.Error(err))
+	} else if reloaded {
+		b.log.Info("Blocklist reloaded", logger.Any("file", b.path), logger.Any("entries", b.entries.Load().len()))
+	}
+}
+
+// reload loads the file if its content changed, or regardless when forced,
+// and reports whether the entries were replaced. Unless forced, a file that
+// cannot be read is only reported when it becomes unreadable, so that a
+// missing file is not logged on every tick.
+func (b *Blocklist) reload(force bool) (bool, error) {
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		if b.unreadable && !force {
+			return false, nil
+		}
+		b.unreadable = true
+		return false, err
+	}
+	b.unreadable = false
+
+	sum := sha256.Sum256(data)
+	if !force && sum == b.sum {
+		return false, nil
+	}
+	b.sum = sum
+
+	e, skipped, err := parse(bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	if skipped > 0 {
+		b.log.Warn("Skipped invalid blocklist entries", logger.Any("file", b.path), logger.Any("skipped", skipped))
+	}
+
+	b.entries.Store(e)
+	b.metrics.BlocklistEntries.Set(float64(e.len()))
+
+	return true, nil
+}
+
+// parse reads entries in hosts-file format ("0.0.0.0 evil.example
+// www.evil.example") or one domain or URL per line. Text from a # at the
+// start of a field is a comment. Published lists are rarely spotless, so
+// entries that do not parse are skipped and counted rather than rejecting
+// the whole file.
+func parse(r io.Reader) (*entries, int, error) {
+	e := &entries{domains: domainset.Set{}, urls: map[string]struct{}{}}
+	skipped := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		for i, f := range fields {
+			if strings.HasPrefix(f, "#") {
+				fields = fields[:i]
+				break
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		if _, err := netip.ParseAddr(fields[0]); err == nil && len(fields) > 1 {
+			fields = fields[1:]
+		}
+		for _, f := range fields {
+			if !e.add(f) {
+				skipped++
+			}
+		}
+	}
+
+	return e, skipped, scanner.Err()
+}
+
+func (e *entries) add(entry string) bool {
+	if strings.Contains(entry, "://") {
+		normalized, err := destination.Normalize(entry)
+		if err != nil {
+			return false
+		}
+		u, _ := url.Parse(normalized)
+		u.Fragment, u.RawFragment = "", ""
+		e.urls[u.String()] = struct{}{}
+		return true
+	}
+
+	if _, ok := localNames[strings.ToLower(entry)]; ok {
+		return true
+	}
+	return e.domains.Add(entry) == nil
+}
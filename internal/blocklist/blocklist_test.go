@@ -0,0 +1,182 @@
+package blocklist_test
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/blocklist"
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+)
+
+const list = `# hosts-file entries
+127.0.0.1 localhost
+0.0.0.0 evil.example www.phish.test # trailing comment
+0.0.0.0   bücher.example
+
+# plain entries
+malware.test
+https://Files.Example:443/./payload.exe
+https://share.example/phish?id=1#ignored
+not_a_domain!
+`
+
+func newMetrics() *metrics.PrometheusMetrics {
+	return &metrics.PrometheusMetrics{
+		BlocklistHitsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "hits"}, []string{"stage"}),
+		BlocklistEntries:   prometheus.NewGauge(prometheus.GaugeOpts{Name: "entries"}),
+	}
+}
+
+func newBlocklist(t *testing.T, content string, interval time.Duration) (*blocklist.Blocklist, *metrics.PrometheusMetrics, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "blocklist")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	m := newMetrics()
+	b, err := blocklist.New(config.BlocklistConfig{File: path, ReloadInterval: interval}, logger.MustInit(false), m)
+	require.NoError(t, err)
+	return b, m, path
+}
+
+func TestBlocklist_Blocked(t *testing.T) {
+	t.Parallel()
+
+	b, m, _ := newBlocklist(t, list, time.Hour)
+	ctx := context.Background()
+
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{"https://evil.example/", true},
+		{"https://login.evil.example/account", true},
+		{"https://www.phish.test/", true},
+		{"https://phish.test/", false},
+		{"https://xn--bcher-kva.example/", true},
+		{"http://cdn.malware.test/x.js", true},
+		{"https://files.example/payload.exe", true},
+		{"https://files.example/payload.exe?mirror=2#top", true},
+		{"https://files.example/other.exe", false},
+		{"https://share.example/phish?id=1", true},
+		{"https://share.example/phish?id=2", false},
+		{"https://notevil.example/", false},
+		{"https://localhost.test.com/", false},
+		{"https://test.com/", false},
+	}
+
+	hits := 0
+	for _, tt := range tests {
+		require.Equal(t, tt.blocked, b.Blocked(ctx, "create", tt.url), tt.url)
+		if tt.blocked {
+			hits++
+		}
+	}
+
+	require.Equal(t, float64(hits), testutil.ToFloat64(m.BlocklistHitsTotal.WithLabelValues("create")))
+	require.Equal(t, float64(6), testutil.ToFloat64(m.BlocklistEntries))
+}
+
+func TestBlocklist_Empty(t *testing.T) {
+	t.Parallel()
+
+	b, err := blocklist.New(config.BlocklistConfig{}, logger.MustInit(false), newMetrics())
+	require.NoError(t, err)
+	require.False(t, b.Blocked(context.Background(), "redirect", "https://evil.example/"))
+
+	_, err = blocklist.New(config.BlocklistConfig{File: filepath.Join(t.TempDir(), "missing")}, logger.MustInit(false), newMetrics())
+	require.Error(t, err)
+}
+
+// countingLogger counts the errors logged through it.
+type countingLogger struct {
+	logger.Logger
+	errors atomic.Int64
+}
+
+func (l *countingLogger) Error(msg string, fields ...logger.Field) {
+	l.errors.Add(1)
+	l.Logger.Error(msg, fields...)
+}
+
+func TestBlocklist_ReloadsOnChange(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "blocklist")
+	require.NoError(t, os.WriteFile(path, []byte("evil.example\n"), 0o600))
+
+	log := &countingLogger{Logger: logger.MustInit(false)}
+	b, err := blocklist.New(config.BlocklistConfig{File: path, ReloadInterval: 10 * time.Millisecond}, log, newMetrics())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx)
+
+	// A rewrite is noticed even if it keeps the modification time.
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("phish.test\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+
+	require.Eventually(t, func() bool {
+		return b.Blocked(ctx, "redirect", "https://phish.test/")
+	}, time.Second, 10*time.Millisecond)
+	require.False(t, b.Blocked(ctx, "redirect", "https://evil.example/"))
+
+	// A missing file keeps the entries and is reported once, not on every
+	// tick.
+	require.NoError(t, os.Remove(path))
+	require.Eventually(t, func() bool { return log.errors.Load() == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, int64(1), log.errors.Load())
+	require.True(t, b.Blocked(ctx, "redirect", "https://phish.test/"))
+
+	require.NoError(t, os.WriteFile(path, []byte("malware.test\n"), 0o600))
+	require.Eventually(t, func() bool {
+		return b.Blocked(ctx, "redirect", "https://malware.test/")
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBlocklist_ReloadsOnSIGHUP(t *testing.T) {
+	// Keeps the signal from terminating the test binary before Run has
+	// registered its own handler.
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGHUP)
+	defer signal.Stop(guard)
+
+	b, _, path := newBlocklist(t, "evil.example\n", time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx)
+
+	// The modification time is left alone: only the signal may trigger this
+	// reload.
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("phish.test\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+
+	// Run may not have registered its handler yet, so the signal is repeated
+	// until the reload shows.
+	var killErr error
+	require.Eventually(t, func() bool {
+		if killErr = syscall.Kill(os.Getpid(), syscall.SIGHUP); killErr != nil {
+			return true
+		}
+		return b.Blocked(ctx, "redirect", "https://phish.test/")
+	}, time.Second, 20*time.Millisecond)
+	require.NoError(t, killErr)
+}
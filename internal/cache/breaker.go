@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+)
+
+var ErrCircuitOpen = errors.New("cache circuit open")
+
+type pingCache interface {
+	Cache
+	Ping(ctx context.Context) error
+}
+
+// Breaker stops calling a failing cache after a number of consecutive errors
+// so that callers fall back to the database without waiting for timeouts.
+// While open, the cache is pinged periodically and the breaker closes again
+// as soon as it answers.
+//
+// Deletes that fail or arrive while the breaker is open are kept and retried,
+// and the breaker only closes once all of them went through. Otherwise an
+// entry invalidated during an outage would be served again afterwards.
+type Breaker struct {
+	c             pingCache
+	threshold     int64
+	probeInterval time.Duration
+	probeTimeout  time.Duration
+	failures      atomic.Int64
+	open          atomic.Bool
+	mu            sync.Mutex
+	pending       map[string]struct{}
+	m             *metrics.PrometheusMetrics
+	log           logger.Logger
+}
+
+func NewBreaker(c pingCache, cfg config.BreakerConfig, m *metrics.PrometheusMetrics, log logger.Logger) *Breaker {
+	return &Breaker{
+		c:             c,
+		threshold:     int64(cfg.FailureThreshold),
+		probeInterval: cfg.ProbeInterval,
+		probeTimeout:  cfg.ProbeTimeout,
+		m:             m,
+		log:           log,
+	}
+}
+
+func (b *Breaker) Get(ctx context.Context, key string) ([]byte, error) {
+	if b.open.Load() {
+		return nil, ErrCircuitOpen
+	}
+	value, err := b.c.Get(ctx, key)
+	b.record(err)
+	return value, err
+}
+
+func (b *Breaker) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if b.open.Load() {
+		return ErrCircuitOpen
+	}
+	err := b.c.Set(ctx, key, value, ttl)
+	b.record(err)
+	return err
+}
+
+func (b *Breaker) Delete(ctx context.Context, key string) error {
+	if b.open.Load() {
+		b.keep(key)
+		return ErrCircuitOpen
+	}
+	err := b.c.Delete(ctx, key)
+	b.record(err)
+	if err != nil {
+		b.keep(key)
+	}
+	return err
+}
+
+// Trip opens the breaker regardless of the failure count.
+func (b *Breaker) Trip() {
+	if b.open.CompareAndSwap(false, true) {
+		b.m.CacheBreakerState.Set(1)
+		b.log.Warn("Cache circuit opened")
+	}
+}
+
+// Run probes the cache while the breaker is open until ctx is cancelled.
+func (b *Breaker) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if b.open.Load() {
+				b.probe(ctx)
+			} else {
+				b.retryDeletes(ctx)
+			}
+		}
+	}
+}
+
+func (b *Breaker) probe(ctx context.Context) {
+	probeCtx, cancel := context.WithTimeout(ctx, b.probeTimeout)
+	defer cancel()
+
+	if err := b.c.Ping(probeCtx); err != nil {
+		b.log.Debug("Cache probe failed", logger.Error(err))
+		return
+	}
+	if err := b.flush(probeCtx); err != nil {
+		b.log.Debug("Cache probe failed", logger.Error(err))
+		return
+	}
+
+	b.failures.Store(0)
+	if b.open.CompareAndSwap(true, false) {
+		b.m.CacheBreakerState.Set(0)
+		b.log.Info("Cache circuit closed")
+	}
+}
+
+func (b *Breaker) retryDeletes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, b.probeTimeout)
+	defer cancel()
+
+	if err := b.flush(ctx); err != nil {
+		b.log.Warn("Unable to retry cache deletes", logger.Error(err))
+	}
+}
+
+// flush retries the kept deletes, keeping those that fail again.
+func (b *Breaker) flush(ctx context.Context) error {
+	b.mu.Lock()
+	keys := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	var err error
+	for key := range keys {
+		if err == nil {
+			if err = b.c.Delete(ctx, key); err == nil {
+				continue
+			}
+		}
+		b.keep(key)
+	}
+	return err
+}
+
+func (b *Breaker) keep(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending == nil {
+		b.pending = make(map[string]struct{})
+	}
+	b.pending[key] = struct{}{}
+}
+
+func (b *Breaker) record(err error) {
+	// Misses and abandoned requests say nothing about the cache's health.
+	if err == nil || errors.Is(err, ErrMiss) || errors.Is(err, context.Canceled) {
+		b.failures.Store(0)
+		return
+	}
+	if b.failures.Add(1) >= b.threshold {
+		b.Trip()
+	}
+}
@@ -0,0 +1,133 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/cache"
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+)
+
+var errDown = errors.New("connection refused")
+
+type flakyCache struct {
+	down    atomic.Bool
+	calls   atomic.Int64
+	mu      sync.Mutex
+	deleted []string
+}
+
+func (f *flakyCache) Get(context.Context, string) ([]byte, error) {
+	f.calls.Add(1)
+	if f.down.Load() {
+		return nil, errDown
+	}
+	return nil, cache.ErrMiss
+}
+
+func (f *flakyCache) Set(context.Context, string, []byte, time.Duration) error {
+	return nil
+}
+
+func (f *flakyCache) Delete(_ context.Context, key string) error {
+	if f.down.Load() {
+		return errDown
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleted = append(f.deleted, key)
+	return nil
+}
+
+func (f *flakyCache) deletedKeys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.deleted)
+}
+
+func (f *flakyCache) Ping(context.Context) error {
+	if f.down.Load() {
+		return errDown
+	}
+	return nil
+}
+
+func TestBreaker(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	m := &metrics.PrometheusMetrics{CacheBreakerState: prometheus.NewGauge(prometheus.GaugeOpts{Name: "breaker"})}
+	c := &flakyCache{}
+	c.down.Store(true)
+
+	b := cache.NewBreaker(c, config.BreakerConfig{
+		FailureThreshold: 3,
+		ProbeInterval:    10 * time.Millisecond,
+		ProbeTimeout:     time.Second,
+	}, m, logger.MustInit(false))
+
+	for range 3 {
+		_, err := b.Get(ctx, "key")
+		require.ErrorIs(t, err, errDown)
+	}
+
+	_, err := b.Get(ctx, "key")
+	require.ErrorIs(t, err, cache.ErrCircuitOpen)
+	require.Equal(t, int64(3), c.calls.Load())
+	require.Equal(t, 1.0, testutil.ToFloat64(m.CacheBreakerState))
+
+	go b.Run(ctx)
+	c.down.Store(false)
+
+	require.Eventually(t, func() bool {
+		_, err := b.Get(ctx, "key")
+		return errors.Is(err, cache.ErrMiss)
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 0.0, testutil.ToFloat64(m.CacheBreakerState))
+}
+
+func TestBreakerKeepsDeletes(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	m := &metrics.PrometheusMetrics{CacheBreakerState: prometheus.NewGauge(prometheus.GaugeOpts{Name: "breaker"})}
+	c := &flakyCache{}
+	b := cache.NewBreaker(c, config.BreakerConfig{
+		FailureThreshold: 3,
+		ProbeInterval:    10 * time.Millisecond,
+		ProbeTimeout:     time.Second,
+	}, m, logger.MustInit(false))
+
+	// A delete that fails while the breaker is closed is retried on the next
+	// tick.
+	c.down.Store(true)
+	require.ErrorIs(t, b.Delete(ctx, "link:failed"), errDown)
+
+	// One issued while the breaker is open is not even attempted.
+	b.Trip()
+	require.ErrorIs(t, b.Delete(ctx, "link:skipped"), cache.ErrCircuitOpen)
+
+	go b.Run(ctx)
+	c.down.Store(false)
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(m.CacheBreakerState) == 0
+	}, time.Second, 10*time.Millisecond)
+	// Both deletes went through before the breaker let reads back in.
+	require.ElementsMatch(t, []string{"link:failed", "link:skipped"}, c.deletedKeys())
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+)
+
+const (
+	invalidationStagePublish = "publish"
+	invalidationStageReceive = "receive"
+	invalidationStageDecode  = "decode"
+
+	maxResubscribeDelay = 30 * time.Second
+)
+
+type evicter interface {
+	Delete(ctx context.Context, key string) error
+	Purge()
+}
+
+type invalidation struct {
+	Key    string `json:"key"`
+	SentAt int64  `json:"sent_at"`
+}
+
+// Invalidator broadcasts evicted keys over Redis pub/sub so that every
+// instance drops them from its in-process cache.
+type Invalidator struct {
+	c       *redis.Client
+	channel string
+	local   evicter
+	m       *metrics.PrometheusMetrics
+	log     logger.Logger
+}
+
+func NewInvalidator(r *RedisCache, local evicter, cfg config.LocalCacheConfig, m *metrics.PrometheusMetrics, log logger.Logger) *Invalidator {
+	return &Invalidator{
+		c:       r.c,
+		channel: cfg.InvalidationChannel,
+		local:   local,
+		m:       m,
+		log:     log,
+	}
+}
+
+func (i *Invalidator) Publish(ctx context.Context, key string) error {
+	data, _ := json.Marshal(invalidation{Key: key, SentAt: time.Now().UnixNano()})
+	if err := i.c.Publish(ctx, i.channel, data).Err(); err != nil {
+		i.m.CacheInvalidationErrorsTotal.WithLabelValues(invalidationStagePublish).Inc()
+		return err
+	}
+	return nil
+}
+
+// Run consumes invalidations until ctx is cancelled. The connection is
+// re-established after failures; as messages may have been missed in the
+// meantime, the local cache is purged once the subscription is restored.
+func (i *Invalidator) Run(ctx context.Context) {
+	ps := i.c.Subscribe(ctx, i.channel)
+	go func() {
+		<-ctx.Done()
+		_ = ps.Close()
+	}()
+
+	var (
+		failures int
+		delay    time.Duration
+	)
+	for {
+		msg, err := ps.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			delay = min(time.Duration(failures)*time.Second, maxResubscribeDelay)
+			i.m.CacheInvalidationErrorsTotal.WithLabelValues(invalidationStageReceive).Inc()
+			i.log.Warn("Cache invalidation subscription failed",
+				logger.Any("attempt", failures),
+				logger.Any("delay", delay),
+				logger.Error(err),
+			)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			continue
+		}
+
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			if failures > 0 {
+				i.local.Purge()
+				i.log.Info("Cache invalidation subscription restored", logger.Any("channel", i.channel))
+			}
+			failures = 0
+		case *redis.Message:
+			i.handle(ctx, msg.Payload)
+		}
+	}
+}
+
+func (i *Invalidator) handle(ctx context.Context, payload string) {
+	var inv invalidation
+	if err := json.Unmarshal([]byte(payload), &inv); err != nil || inv.Key == "" {
+		i.m.CacheInvalidationErrorsTotal.WithLabelValues(invalidationStageDecode).Inc()
+		i.log.Warn("Malformed cache invalidation", logger.Any("payload", payload))
+		return
+	}
+
+	_ = i.local.Delete(ctx, inv.Key)
+	i.m.CacheInvalidationLagSeconds.Observe(time.Since(time.Unix(0, inv.SentAt)).Seconds())
+}
@@ -0,0 +1,104 @@
+package cache_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/cache"
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+)
+
+type recordingEvicter struct {
+	mu      sync.Mutex
+	deleted []string
+	purges  int
+}
+
+func (e *recordingEvicter) Delete(_ context.Context, key string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deleted = append(e.deleted, key)
+	return nil
+}
+
+func (e *recordingEvicter) Purge() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.purges++
+}
+
+func (e *recordingEvicter) state() ([]string, int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.deleted), e.purges
+}
+
+func TestInvalidator(t *testing.T) {
+	t.Parallel()
+
+	const channel = "link-invalidations"
+
+	s := miniredis.RunT(t)
+	log := logger.MustInit(false)
+	rc, err := cache.Init(config.CacheConfig{
+		Host:           s.Host(),
+		Port:           s.Port(),
+		PingTimeout:    time.Second,
+		PingMaxRetries: 1,
+	}, log)
+	require.NoError(t, err)
+
+	m := &metrics.PrometheusMetrics{
+		CacheInvalidationLagSeconds:  prometheus.NewHistogram(prometheus.HistogramOpts{Name: "lag"}),
+		CacheInvalidationErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "errors"}, []string{"stage"}),
+	}
+	ev := &recordingEvicter{}
+	inv := cache.NewInvalidator(rc, ev, config.LocalCacheConfig{InvalidationChannel: channel}, m, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go inv.Run(ctx)
+
+	subscribed := func() bool { return s.PubSubNumSub(channel)[channel] == 1 }
+	require.Eventually(t, subscribed, time.Second, 10*time.Millisecond)
+
+	// Published keys are evicted from the local cache.
+	require.NoError(t, inv.Publish(ctx, "link:Code123"))
+	require.Eventually(t, func() bool {
+		deleted, _ := ev.state()
+		return slices.Equal(deleted, []string{"link:Code123"})
+	}, time.Second, 10*time.Millisecond)
+
+	// Malformed payloads are counted and evict nothing.
+	s.Publish(channel, "not json")
+	s.Publish(channel, `{"key":""}`)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(m.CacheInvalidationErrorsTotal.WithLabelValues("decode")) == 2
+	}, time.Second, 10*time.Millisecond)
+	deleted, purges := ev.state()
+	require.Len(t, deleted, 1)
+	require.Zero(t, purges)
+
+	// Messages may be lost while the connection is down, so the local cache
+	// is purged once the subscription is back.
+	s.Close()
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(m.CacheInvalidationErrorsTotal.WithLabelValues("receive")) >= 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, s.Restart())
+	require.Eventually(t, func() bool {
+		_, purges := ev.state()
+		return purges == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, subscribed, time.Second, 10*time.Millisecond)
+}
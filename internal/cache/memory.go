@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/metrics"
+)
+
+const (
+	evictionCapacity = "capacity"
+	evictionExpired  = "expired"
+)
+
+var ErrMiss = errors.New("cache miss")
+
+// Memory is a bounded in-process LRU cache of decoded values. Entries are
+// evicted once they outlive their TTL or when the entry or byte limit is
+// exceeded; a zero limit disables it. Values are handed out as stored, so
+// they must not be modified through any reference they hold.
+type Memory[V any] struct {
+	mu         sync.Mutex
+	ll         *list.List
+	items      map[string]*list.Element
+	size       int
+	sizeOf     func(V) int
+	maxEntries int
+	maxBytes   int
+	ttl        time.Duration
+	m          *metrics.PrometheusMetrics
+}
+
+type memoryEntry[V any] struct {
+	key       string
+	value     V
+	size      int
+	expiresAt time.Time
+}
+
+// NewMemory creates a cache that accounts for every value with sizeOf bytes
+// on top of its key.
+func NewMemory[V any](cfg config.LocalCacheConfig, sizeOf func(V) int, m *metrics.PrometheusMetrics) *Memory[V] {
+	return &Memory[V]{
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		sizeOf:     sizeOf,
+		maxEntries: cfg.MaxEntries,
+		maxBytes:   cfg.MaxBytes,
+		ttl:        cfg.Ttl,
+		m:          m,
+	}
+}
+
+func (c *Memory[V]) Get(_ context.Context, key string) (V, error) {
+	var zero V
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.m.LocalCacheRequestsTotal.WithLabelValues(cacheResultMiss).Inc()
+		return zero, ErrMiss
+	}
+
+	e := el.Value.(*memoryEntry[V])
+	if !time.Now().Before(e.expiresAt) {
+		c.remove(el, evictionExpired)
+		c.m.LocalCacheRequestsTotal.WithLabelValues(cacheResultMiss).Inc()
+		return zero, ErrMiss
+	}
+
+	c.ll.MoveToFront(el)
+	c.m.LocalCacheRequestsTotal.WithLabelValues(cacheResultHit).Inc()
+	return e.value, nil
+}
+
+// Set stores value for the shorter of ttl and the cache's own TTL.
+func (c *Memory[V]) Set(_ context.Context, key string, value V, ttl time.Duration) error {
+	ttl = min(ttl, c.ttl)
+	if ttl <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el, "")
+	}
+
+	e := &memoryEntry[V]{
+		key:       key,
+		value:     value,
+		size:      len(key) + c.sizeOf(value),
+		expiresAt: time.Now().Add(ttl),
+	}
+	c.items[key] = c.ll.PushFront(e)
+	c.size += e.size
+
+	for c.overLimit() {
+		c.remove(c.ll.Back(), evictionCapacity)
+	}
+	c.updateGauges()
+
+	return nil
+}
+
+func (c *Memory[V]) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el, "")
+		c.updateGauges()
+	}
+	return nil
+}
+
+// Purge drops every entry.
+func (c *Memory[V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	clear(c.items)
+	c.size = 0
+	c.updateGauges()
+}
+
+func (c *Memory[V]) overLimit() bool {
+	if c.ll.Len() == 0 {
+		return false
+	}
+	return (c.maxEntries > 0 && c.ll.Len() > c.maxEntries) ||
+		(c.maxBytes > 0 && c.size > c.maxBytes)
+}
+
+func (c *Memory[V]) remove(el *list.Element, reason string) {
+	e := c.ll.Remove(el).(*memoryEntry[V])
+	delete(c.items, e.key)
+	c.size -= e.size
+	if reason != "" {
+		c.m.LocalCacheEvictionsTotal.WithLabelValues(reason).Inc()
+	}
+}
+
+func (c *Memory[V]) updateGauges() {
+	c.m.LocalCacheEntries.Set(float64(c.ll.Len()))
+	c.m.LocalCacheBytes.Set(float64(c.size))
+}
@@ -0,0 +1,83 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/cache"
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/metrics"
+)
+
+func newMetrics() *metrics.PrometheusMetrics {
+	return &metrics.PrometheusMetrics{
+		LocalCacheRequestsTotal:  prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests"}, []string{"result"}),
+		LocalCacheEvictionsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "evictions"}, []string{"reason"}),
+		LocalCacheEntries:        prometheus.NewGauge(prometheus.GaugeOpts{Name: "entries"}),
+		LocalCacheBytes:          prometheus.NewGauge(prometheus.GaugeOpts{Name: "bytes"}),
+	}
+}
+
+func byteSize(v []byte) int {
+	return len(v)
+}
+
+func TestMemory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("evicts least recently used entry", func(t *testing.T) {
+		t.Parallel()
+
+		m := newMetrics()
+		c := cache.NewMemory(config.LocalCacheConfig{MaxEntries: 2, Ttl: time.Minute}, byteSize, m)
+
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+		require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+		_, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+
+		_, err = c.Get(ctx, "b")
+		require.ErrorIs(t, err, cache.ErrMiss)
+		got, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		require.Equal(t, []byte("1"), got)
+		require.Equal(t, 1.0, testutil.ToFloat64(m.LocalCacheEvictionsTotal.WithLabelValues("capacity")))
+		require.Equal(t, 2.0, testutil.ToFloat64(m.LocalCacheEntries))
+	})
+
+	t.Run("evicts by size", func(t *testing.T) {
+		t.Parallel()
+
+		m := newMetrics()
+		c := cache.NewMemory(config.LocalCacheConfig{MaxBytes: 10, Ttl: time.Minute}, byteSize, m)
+
+		require.NoError(t, c.Set(ctx, "a", []byte("12345"), time.Minute))
+		require.NoError(t, c.Set(ctx, "b", []byte("12345"), time.Minute))
+
+		_, err := c.Get(ctx, "a")
+		require.ErrorIs(t, err, cache.ErrMiss)
+		require.Equal(t, 6.0, testutil.ToFloat64(m.LocalCacheBytes))
+	})
+
+	t.Run("expires entries with own ttl", func(t *testing.T) {
+		t.Parallel()
+
+		m := newMetrics()
+		c := cache.NewMemory(config.LocalCacheConfig{Ttl: 10 * time.Millisecond}, byteSize, m)
+
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Hour))
+		time.Sleep(20 * time.Millisecond)
+
+		_, err := c.Get(ctx, "a")
+		require.ErrorIs(t, err, cache.ErrMiss)
+		require.Equal(t, 1.0, testutil.ToFloat64(m.LocalCacheEvictionsTotal.WithLabelValues("expired")))
+	})
+}
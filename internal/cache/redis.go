@@ -2,9 +2,11 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"net"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/domovonok/url-shortener/internal/config"
@@ -12,11 +14,12 @@ import (
 )
 
 type RedisCache struct {
-	c   *redis.Client
-	ttl time.Duration
+	c *redis.Client
 }
 
-func MustInit(cfg config.CacheConfig, log logger.Logger) *RedisCache {
+// Init connects to Redis. A failed ping is reported alongside a usable client
+// so that the service can start without Redis and reconnect later.
+func Init(cfg config.CacheConfig, log logger.Logger) (*RedisCache, error) {
 	opts := &redis.Options{
 		Addr:     net.JoinHostPort(cfg.Host, cfg.Port),
 		Username: cfg.Username,
@@ -24,6 +27,9 @@ func MustInit(cfg config.CacheConfig, log logger.Logger) *RedisCache {
 		DB:       cfg.DB,
 	}
 	c := redis.NewClient(opts)
+	if err := redisotel.InstrumentTracing(c); err != nil {
+		log.Warn("Unable to instrument redis client", logger.Error(err))
+	}
 
 	redis.SetLogger(logger.NewRedisLogger(log))
 
@@ -51,26 +57,39 @@ func MustInit(cfg config.CacheConfig, log logger.Logger) *RedisCache {
 	}
 
 	if pingErr != nil {
-		_ = c.Close()
-		log.Fatal("Unable to ping redis", logger.Error(pingErr))
+		return &RedisCache{c: c}, pingErr
 	}
 
 	log.Info("Redis connection established", logger.Any("addr", opts.Addr))
-	return &RedisCache{c: c, ttl: cfg.Ttl}
+	return &RedisCache{c: c}, nil
 }
 
 func (r *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
-	return r.c.Get(ctx, key).Bytes()
+	value, err := r.c.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
 }
 
-func (r *RedisCache) Set(ctx context.Context, key string, value []byte) error {
-	return r.c.Set(ctx, key, value, r.ttl).Err()
+func (r *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.c.Set(ctx, key, value, ttl).Err()
 }
 
 func (r *RedisCache) Delete(ctx context.Context, key string) error {
 	return r.c.Del(ctx, key).Err()
 }
 
+// Client exposes the underlying connection for components that need more
+// than key-value access.
+func (r *RedisCache) Client() *redis.Client {
+	return r.c
+}
+
+func (r *RedisCache) Ping(ctx context.Context) error {
+	return r.c.Ping(ctx).Err()
+}
+
 func (r *RedisCache) Close() error {
 	return r.c.Close()
 }
@@ -0,0 +1,153 @@
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Forwarding headers a trusted proxy may set.
+const (
+	HeaderForwarded     = "Forwarded"
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderXRealIP       = "X-Real-IP"
+)
+
+// Resolver determines the address of the client behind a request. Only the
+// one forwarding header the trusted proxies set is honoured, only when the
+// connection comes from a trusted proxy, and only up to the first hop that is
+// not a trusted proxy itself. Any other forwarding header may come from the
+// client and is ignored.
+type Resolver struct {
+	trusted []netip.Prefix
+	header  string
+}
+
+// New builds a resolver trusting a comma-separated list of CIDRs or single
+// addresses to set header.
+func New(trustedProxies, header string) (*Resolver, error) {
+	trusted, err := ParsePrefixes(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	for _, h := range []string{HeaderForwarded, HeaderXForwardedFor, HeaderXRealIP} {
+		if strings.EqualFold(strings.TrimSpace(header), h) {
+			return &Resolver{trusted: trusted, header: h}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported trusted proxy header %q", header)
+}
+
+// ParsePrefixes parses a comma-separated list of CIDRs or single addresses.
+func ParsePrefixes(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for item := range strings.SplitSeq(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if !strings.Contains(item, "/") {
+			addr, err := netip.ParseAddr(item)
+			if err != nil {
+				return nil, err
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+// Resolve returns the client address. It returns the zero address if the
+// peer address cannot be parsed or if a hop up to the client is malformed or
+// withheld, as with for=unknown; such a client cannot be told apart from
+// others and is not trusted with anything.
+func (res *Resolver) Resolve(r *http.Request) netip.Addr {
+	remote := parseNode(r.RemoteAddr)
+	if !remote.IsValid() || !res.isTrusted(remote) {
+		return remote
+	}
+
+	var hops []string
+	switch res.header {
+	case HeaderForwarded:
+		hops = forwardedFor(r.Header.Values(HeaderForwarded))
+	case HeaderXForwardedFor:
+		hops = splitList(r.Header.Values(HeaderXForwardedFor))
+	case HeaderXRealIP:
+		if value := strings.TrimSpace(r.Header.Get(HeaderXRealIP)); value != "" {
+			hops = []string{value}
+		}
+	}
+
+	// Hops are appended by every proxy, so the client is the rightmost
+	// address that was not added by one of ours.
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		client = parseNode(hops[i])
+		if !client.IsValid() || !res.isTrusted(client) {
+			break
+		}
+	}
+
+	return client
+}
+
+func (res *Resolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range res.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor extracts the for= parameters of RFC 7239 Forwarded headers.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, element := range splitList(values) {
+		for pair := range strings.SplitSeq(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				hops = append(hops, strings.Trim(value, `"`))
+			}
+		}
+	}
+	return hops
+}
+
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// parseNode parses an address that may carry a port and IPv6 brackets.
+func parseNode(node string) netip.Addr {
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		node = host
+	}
+	addr, err := netip.ParseAddr(strings.Trim(node, "[]"))
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
@@ -0,0 +1,130 @@
+package clientip_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/clientip"
+)
+
+func TestResolverResolve(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		header  string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:   "direct client",
+			remote: "203.0.113.7:5123",
+			want:   "203.0.113.7",
+		},
+		{
+			name:    "headers from untrusted peer are ignored",
+			remote:  "203.0.113.7:5123",
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "x-forwarded-for through trusted proxies",
+			remote:  "10.0.0.2:5123",
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.9, 198.51.100.1, 10.0.0.3"},
+			want:    "198.51.100.1",
+		},
+		{
+			name:    "x-real-ip from trusted proxy",
+			header:  "x-real-ip",
+			remote:  "192.0.2.1:5123",
+			headers: map[string]string{"X-Real-IP": "198.51.100.1"},
+			want:    "198.51.100.1",
+		},
+		{
+			name:   "forwarded from trusted proxy",
+			header: "Forwarded",
+			remote: "10.0.0.2:5123",
+			headers: map[string]string{
+				"Forwarded": `for="[2001:db8::17]:4711";proto=https, for=10.0.0.3`,
+			},
+			want: "2001:db8::17",
+		},
+		{
+			name:   "other forwarding headers are ignored",
+			remote: "10.0.0.2:5123",
+			headers: map[string]string{
+				"Forwarded":       "for=198.51.100.66",
+				"X-Real-IP":       "198.51.100.77",
+				"X-Forwarded-For": "198.51.100.1",
+			},
+			want: "198.51.100.1",
+		},
+		{
+			name:   "spoofed forwarded header does not win",
+			header: "Forwarded",
+			remote: "10.0.0.2:5123",
+			headers: map[string]string{
+				"Forwarded":       "for=198.51.100.1",
+				"X-Forwarded-For": "198.51.100.66",
+			},
+			want: "198.51.100.1",
+		},
+		{
+			name:    "malformed hop leaves the client untrusted",
+			remote:  "10.0.0.2:5123",
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.1, bogus, 10.0.0.3"},
+			want:    "invalid IP",
+		},
+		{
+			name:    "unknown forwarded hop leaves the client untrusted",
+			header:  "Forwarded",
+			remote:  "10.0.0.2:5123",
+			headers: map[string]string{"Forwarded": "for=unknown, for=10.0.0.3"},
+			want:    "invalid IP",
+		},
+		{
+			name:    "only trusted hops",
+			remote:  "10.0.0.2:5123",
+			headers: map[string]string{"X-Forwarded-For": "10.0.0.4, 10.0.0.3"},
+			want:    "10.0.0.4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			header := tt.header
+			if header == "" {
+				header = clientip.HeaderXForwardedFor
+			}
+			resolver, err := clientip.New("10.0.0.0/8, 192.0.2.1", header)
+			require.NoError(t, err)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			require.Equal(t, tt.want, resolver.Resolve(r).String())
+		})
+	}
+}
+
+func TestNewRejectsInvalidProxies(t *testing.T) {
+	t.Parallel()
+
+	_, err := clientip.New("10.0.0.0/33", clientip.HeaderXForwardedFor)
+	require.Error(t, err)
+}
+
+func TestNewRejectsUnsupportedHeader(t *testing.T) {
+	t.Parallel()
+
+	_, err := clientip.New("10.0.0.0/8", "True-Client-IP")
+	require.Error(t, err)
+}
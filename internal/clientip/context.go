@@ -0,0 +1,17 @@
+package clientip
+
+import (
+	"context"
+	"net/netip"
+)
+
+type addrKey struct{}
+
+func WithAddr(ctx context.Context, addr netip.Addr) context.Context {
+	return context.WithValue(ctx, addrKey{}, addr)
+}
+
+func FromContext(ctx context.Context) (netip.Addr, bool) {
+	addr, ok := ctx.Value(addrKey{}).(netip.Addr)
+	return addr, ok && addr.IsValid()
+}
@@ -34,6 +34,24 @@ type ServerConfig struct {
 	Port                    string
 	PprofPort               string
 	GracefulShutdownTimeout time.Duration
+	TrustedProxies          string
+	TrustedProxyHeader      string
+	PublicBaseURL           string
+}
+
+type LocalCacheConfig struct {
+	Enabled    bool
+	MaxEntries int
+	MaxBytes   int
+	Ttl        time.Duration
+
+	InvalidationChannel string
+}
+
+type BreakerConfig struct {
+	FailureThreshold int
+	ProbeInterval    time.Duration
+	ProbeTimeout     time.Duration
 }
 
 type CacheConfig struct {
@@ -46,22 +64,102 @@ type CacheConfig struct {
 	PingMaxRetries int
 	PingRetryDelay time.Duration
 	Ttl            time.Duration
+	NegativeTtl    time.Duration
+	Coalesce       bool
+	Local          LocalCacheConfig
+	Breaker        BreakerConfig
 }
 
-type RateLimitConfig struct {
+type CodecConfig struct {
+	Type       string
+	Keys       string
+	KeyVersion int
+}
+
+type ClicksConfig struct {
+	BufferSize    int
+	BatchSize     int
+	FlushInterval time.Duration
+	WriteTimeout  time.Duration
+}
+
+type BucketConfig struct {
 	Capacity   int
 	RefillRate int
 }
 
+// RateLimitPolicy holds the bucket limits of every client tier, keyed by
+// tier name.
+type RateLimitPolicy struct {
+	Tiers map[string]BucketConfig
+}
+
+// RateLimitConfig holds the named policies applied to route groups.
+type RateLimitConfig struct {
+	Backend      string
+	Policies     map[string]RateLimitPolicy
+	IdleTimeout  time.Duration
+	RedisTimeout time.Duration
+}
+
+type IPListConfig struct {
+	Allow string
+	Deny  string
+}
+
+// IPFilterConfig holds the allow and deny lists of every filtered route
+// group, keyed by group name.
+type IPFilterConfig struct {
+	Groups         map[string]IPListConfig
+	File           string
+	ReloadInterval time.Duration
+}
+
+type TracingConfig struct {
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	File        string
+	SampleRatio float64
+}
+
+// DestinationConfig holds the rules the destination of a link must pass.
+// Domains match themselves and all of their subdomains.
+type DestinationConfig struct {
+	AllowPrivate   bool
+	DeniedDomains  string
+	DeniedNetworks string
+	Resolve        bool
+	ResolveTimeout time.Duration
+}
+
+// BlocklistConfig points at a file of malicious domains and URLs, which is
+// reloaded when it changes or on SIGHUP.
+type BlocklistConfig struct {
+	File           string
+	ReloadInterval time.Duration
+}
+
 type Config struct {
 	Debug         bool
 	Server        ServerConfig
 	DB            DBConfig
 	Cache         CacheConfig
+	Codec         CodecConfig
+	Clicks        ClicksConfig
 	RateLimit     RateLimitConfig
+	IPFilter      IPFilterConfig
+	Tracing       TracingConfig
+	Destination   DestinationConfig
+	Blocklist     BlocklistConfig
 	MetricsPeriod time.Duration
 }
 
+// defaultDeniedDomains lists well-known URL shorteners, which would otherwise
+// let a link hide its real destination or bounce back to us.
+const defaultDeniedDomains = "bit.ly,bitly.com,tinyurl.com,t.co,goo.gl,ow.ly,is.gd,v.gd,buff.ly," +
+	"rebrand.ly,cutt.ly,shorturl.at,tiny.cc,rb.gy,t.ly,s.id"
+
 func Load() *Config {
 	_ = godotenv.Load()
 	return &Config{
@@ -70,6 +168,9 @@ func Load() *Config {
 			Port:                    getEnvAsString("PORT", "8080"),
 			PprofPort:               getEnvAsString("PPROF_PORT", "6060"),
 			GracefulShutdownTimeout: getEnvAsDuration("GRACEFUL_SHUTDOWN_TIMEOUT", 5*time.Second),
+			TrustedProxies:          getEnvAsString("TRUSTED_PROXIES", ""),
+			TrustedProxyHeader:      getEnvAsString("TRUSTED_PROXY_HEADER", "X-Forwarded-For"),
+			PublicBaseURL:           getEnvAsString("PUBLIC_BASE_URL", ""),
 		},
 		DB: DBConfig{
 			Host:     getEnvAsString("POSTGRES_HOST", "localhost"),
@@ -100,15 +201,115 @@ func Load() *Config {
 			PingMaxRetries: getEnvAsInt("REDIS_PING_MAX_RETRIES", 3),
 			PingRetryDelay: getEnvAsDuration("REDIS_PING_RETRY_DELAY", time.Second),
 			Ttl:            getEnvAsDuration("CACHE_TTL", 10*time.Minute),
+			NegativeTtl:    getEnvAsDuration("CACHE_NEGATIVE_TTL", 30*time.Second),
+			Coalesce:       getEnvAsBool("CACHE_COALESCE", true),
+			Local: LocalCacheConfig{
+				Enabled:    getEnvAsBool("LOCAL_CACHE_ENABLED", false),
+				MaxEntries: getEnvAsInt("LOCAL_CACHE_MAX_ENTRIES", 10000),
+				MaxBytes:   getEnvAsInt("LOCAL_CACHE_MAX_BYTES", 32<<20),
+				Ttl:        getEnvAsDuration("LOCAL_CACHE_TTL", 30*time.Second),
+
+				InvalidationChannel: getEnvAsString("LOCAL_CACHE_INVALIDATION_CHANNEL", "link-invalidations"),
+			},
+			Breaker: BreakerConfig{
+				FailureThreshold: getEnvAsInt("CACHE_BREAKER_FAILURE_THRESHOLD", 5),
+				ProbeInterval:    getEnvAsDuration("CACHE_BREAKER_PROBE_INTERVAL", 5*time.Second),
+				ProbeTimeout:     getEnvAsDuration("CACHE_BREAKER_PROBE_TIMEOUT", time.Second),
+			},
+		},
+		Codec: CodecConfig{
+			Type:       getEnvAsString("LINK_CODEC", "base64"),
+			Keys:       getEnvAsString("LINK_CODEC_KEYS", ""),
+			KeyVersion: getEnvAsInt("LINK_CODEC_KEY_VERSION", 1),
+		},
+		Clicks: ClicksConfig{
+			BufferSize:    getEnvAsInt("CLICKS_BUFFER_SIZE", 10000),
+			BatchSize:     getEnvAsInt("CLICKS_BATCH_SIZE", 500),
+			FlushInterval: getEnvAsDuration("CLICKS_FLUSH_INTERVAL", time.Second),
+			WriteTimeout:  getEnvAsDuration("CLICKS_WRITE_TIMEOUT", 5*time.Second),
 		},
 		RateLimit: RateLimitConfig{
-			Capacity:   getEnvAsInt("RATE_LIMIT_CAPACITY", 100),
-			RefillRate: getEnvAsInt("RATE_LIMIT_REFILL_RATE", 10),
+			Backend: getEnvAsString("RATE_LIMIT_BACKEND", "local"),
+			Policies: map[string]RateLimitPolicy{
+				// Redirects are public and limited per client IP only. The limits
+				// still default to the settings from before there were policies.
+				"redirect": {Tiers: map[string]BucketConfig{
+					"ip": {
+						Capacity:   getEnvAsInt("RATE_LIMIT_REDIRECT_CAPACITY", getEnvAsInt("RATE_LIMIT_CAPACITY", 100)),
+						RefillRate: getEnvAsInt("RATE_LIMIT_REDIRECT_REFILL_RATE", getEnvAsInt("RATE_LIMIT_REFILL_RATE", 10)),
+					},
+				}},
+				"create": getRateLimitPolicy("RATE_LIMIT_CREATE", BucketConfig{10, 1}, BucketConfig{60, 5}),
+				"api":    getRateLimitPolicy("RATE_LIMIT_API", BucketConfig{20, 2}, BucketConfig{200, 20}),
+				// Failed authentications are only ever counted per client IP.
+				"auth": {Tiers: map[string]BucketConfig{
+					"ip": {
+						Capacity:   getEnvAsInt("RATE_LIMIT_AUTH_FAILURES_CAPACITY", 10),
+						RefillRate: getEnvAsInt("RATE_LIMIT_AUTH_FAILURES_REFILL_RATE", 1),
+					},
+				}},
+			},
+			IdleTimeout:  getEnvAsDuration("RATE_LIMIT_IDLE_TIMEOUT", 10*time.Minute),
+			RedisTimeout: getEnvAsDuration("RATE_LIMIT_REDIS_TIMEOUT", 100*time.Millisecond),
+		},
+		IPFilter: IPFilterConfig{
+			Groups: map[string]IPListConfig{
+				"create": {
+					Allow: getEnvAsString("IP_FILTER_CREATE_ALLOW", ""),
+					Deny:  getEnvAsString("IP_FILTER_CREATE_DENY", ""),
+				},
+				"api": {
+					Allow: getEnvAsString("IP_FILTER_API_ALLOW", ""),
+					Deny:  getEnvAsString("IP_FILTER_API_DENY", ""),
+				},
+				"metrics": {
+					Allow: getEnvAsString("IP_FILTER_METRICS_ALLOW", ""),
+					Deny:  getEnvAsString("IP_FILTER_METRICS_DENY", ""),
+				},
+			},
+			File:           getEnvAsString("IP_FILTER_FILE", ""),
+			ReloadInterval: getEnvAsDuration("IP_FILTER_RELOAD_INTERVAL", 30*time.Second),
+		},
+		Tracing: TracingConfig{
+			Exporter:    getEnvAsString("TRACING_EXPORTER", "none"),
+			Endpoint:    getEnvAsString("TRACING_OTLP_ENDPOINT", "localhost:4318"),
+			Insecure:    getEnvAsBool("TRACING_OTLP_INSECURE", true),
+			File:        getEnvAsString("TRACING_FILE", ""),
+			SampleRatio: getEnvAsFloat("TRACING_SAMPLE_RATIO", 1),
+		},
+		Destination: DestinationConfig{
+			AllowPrivate:   getEnvAsBool("DESTINATION_ALLOW_PRIVATE", false),
+			DeniedDomains:  getEnvAsString("DESTINATION_DENIED_DOMAINS", defaultDeniedDomains),
+			DeniedNetworks: getEnvAsString("DESTINATION_DENIED_NETWORKS", ""),
+			Resolve:        getEnvAsBool("DESTINATION_RESOLVE", true),
+			ResolveTimeout: getEnvAsDuration("DESTINATION_RESOLVE_TIMEOUT", 2*time.Second),
+		},
+		Blocklist: BlocklistConfig{
+			File:           getEnvAsString("BLOCKLIST_FILE", ""),
+			ReloadInterval: getEnvAsDuration("BLOCKLIST_RELOAD_INTERVAL", time.Minute),
 		},
 		MetricsPeriod: getEnvAsDuration("METRICS_PERIOD", 5*time.Second),
 	}
 }
 
+// getRateLimitPolicy reads the anonymous and API key tiers of a policy from
+// <prefix>_CAPACITY, <prefix>_REFILL_RATE, <prefix>_KEY_CAPACITY and
+// <prefix>_KEY_REFILL_RATE.
+func getRateLimitPolicy(prefix string, ip, key BucketConfig) RateLimitPolicy {
+	return RateLimitPolicy{
+		Tiers: map[string]BucketConfig{
+			"ip": {
+				Capacity:   getEnvAsInt(prefix+"_CAPACITY", ip.Capacity),
+				RefillRate: getEnvAsInt(prefix+"_REFILL_RATE", ip.RefillRate),
+			},
+			"key": {
+				Capacity:   getEnvAsInt(prefix+"_KEY_CAPACITY", key.Capacity),
+				RefillRate: getEnvAsInt(prefix+"_KEY_REFILL_RATE", key.RefillRate),
+			},
+		},
+	}
+}
+
 func getEnvAs[T any](key string, defaultVal T, parse func(string) (T, error)) T {
 	if val := os.Getenv(key); val != "" {
 		if v, err := parse(val); err == nil {
@@ -134,6 +335,12 @@ func getEnvAsInt32(key string, defaultVal int32) int32 {
 	})
 }
 
+func getEnvAsFloat(key string, defaultVal float64) float64 {
+	return getEnvAs(key, defaultVal, func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	})
+}
+
 func getEnvAsDuration(key string, defaultVal time.Duration) time.Duration {
 	return getEnvAs(key, defaultVal, time.ParseDuration)
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/domovonok/url-shortener/internal/config"
@@ -27,6 +28,7 @@ func MustInit(cfg config.DBConfig, log logger.Logger) *pgxpool.Pool {
 	poolConfig.MinConns = cfg.Pool.MinConns
 	poolConfig.MinIdleConns = cfg.Pool.MinIdleConns
 	poolConfig.HealthCheckPeriod = cfg.Pool.HealthCheckPeriod
+	poolConfig.ConnConfig.Tracer = otelpgx.NewTracer()
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Pool.PingTimeout)
 	defer cancel()
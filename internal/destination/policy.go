@@ -0,0 +1,195 @@
+package destination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/domovonok/url-shortener/internal/clientip"
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/domainset"
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+// Special-purpose ranges that the net/netip predicates do not cover.
+var privateNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // this network
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved and broadcast
+	netip.MustParsePrefix("2001::/32"),      // Teredo, tunnels to an arbitrary IPv4 host
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+}
+
+// IPv6 ranges whose addresses carry an IPv4 address that a host could end up
+// connecting to, with the offset of the IPv4 bytes.
+var ipv4Embedding = []struct {
+	prefix netip.Prefix
+	offset int
+}{
+	{netip.MustParsePrefix("::/96"), 12},        // IPv4-compatible
+	{netip.MustParsePrefix("64:ff9b::/96"), 12}, // NAT64 well-known prefix
+	{netip.MustParsePrefix("2002::/16"), 2},     // 6to4
+}
+
+// Names that only ever resolve inside a local network.
+var privateDomains = []string{"localhost", "localdomain", "local", "internal", "home.arpa"}
+
+// Resolver looks up the addresses of a host; *net.Resolver implements it.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Policy rejects destinations that would let a link reach into private
+// networks or redirect to this or another shortener.
+type Policy struct {
+	allowPrivate bool
+	domains      domainset.Set
+	networks     []netip.Prefix
+	resolver     Resolver
+	timeout      time.Duration
+}
+
+// NewPolicy builds the policy from cfg. The host of publicBaseURL is always
+// denied to prevent redirect loops. A nil resolver, or cfg.Resolve unset,
+// limits the checks to the host itself.
+func NewPolicy(cfg config.DestinationConfig, publicBaseURL string, r Resolver) (*Policy, error) {
+	networks, err := clientip.ParsePrefixes(cfg.DeniedNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid denied networks: %w", err)
+	}
+
+	p := &Policy{
+		allowPrivate: cfg.AllowPrivate,
+		domains:      domainset.Set{},
+		networks:     networks,
+		timeout:      cfg.ResolveTimeout,
+	}
+	if cfg.Resolve {
+		p.resolver = r
+	}
+
+	domains := strings.Split(cfg.DeniedDomains, ",")
+	if !cfg.AllowPrivate {
+		domains = append(domains, privateDomains...)
+	}
+	for _, d := range domains {
+		if strings.TrimSpace(d) == "" {
+			continue
+		}
+		if err := p.domains.Add(d); err != nil {
+			return nil, fmt.Errorf("invalid denied domains: %w", err)
+		}
+	}
+
+	base, err := url.Parse(publicBaseURL)
+	if err != nil || base.Hostname() == "" {
+		return nil, fmt.Errorf("invalid public base url %q", publicBaseURL)
+	}
+	if addr, err := netip.ParseAddr(base.Hostname()); err == nil {
+		p.networks = append(p.networks, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	} else if err := p.domains.Add(base.Hostname()); err != nil {
+		return nil, fmt.Errorf("invalid public base url: %w", err)
+	}
+
+	return p, nil
+}
+
+// Check reports whether a destination in the canonical form produced by
+// Normalize may be shortened. Every address the host resolves to must pass,
+// otherwise a public name could simply point at a private address.
+func (p *Policy) Check(ctx context.Context, destination string) error {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return invalid("is not a valid URL")
+	}
+	host := u.Hostname()
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.checkAddr(addr)
+	}
+	if _, ok := p.domains.Match(host); ok {
+		return denied("points to a denied domain")
+	}
+	if p.resolver == nil {
+		return nil
+	}
+
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return invalid("has a host that does not resolve")
+		}
+		return fmt.Errorf("%w: %w", model.ErrDestinationUnresolved, err)
+	}
+	for _, addr := range addrs {
+		if err := p.checkAddr(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Policy) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap().WithZone("")
+	addrs := []netip.Addr{addr}
+	if v4, ok := embeddedIPv4(addr); ok {
+		addrs = append(addrs, v4)
+	}
+
+	for _, addr := range addrs {
+		if !p.allowPrivate && isPrivate(addr) {
+			return denied("points to a private network")
+		}
+		for _, n := range p.networks {
+			if n.Contains(addr) {
+				return denied("points to a denied network")
+			}
+		}
+	}
+	return nil
+}
+
+// embeddedIPv4 returns the IPv4 address carried by an IPv6 address in one of
+// the translation or tunnelling ranges, such as ::7f00:1 for 127.0.0.1.
+func embeddedIPv4(addr netip.Addr) (netip.Addr, bool) {
+	if !addr.Is6() {
+		return netip.Addr{}, false
+	}
+	b := addr.As16()
+	for _, e := range ipv4Embedding {
+		if e.prefix.Contains(addr) {
+			return netip.AddrFrom4([4]byte(b[e.offset : e.offset+4])), true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+func isPrivate(addr netip.Addr) bool {
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, n := range privateNetworks {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func denied(reason string) error {
+	return model.NewValidationError(model.ErrDestinationDenied, field, reason)
+}
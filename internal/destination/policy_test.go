@@ -0,0 +1,163 @@
+package destination_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/destination"
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+// fakeResolver answers from a fixed table instead of DNS.
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupNetIP(ctx context.Context, _, host string) ([]netip.Addr, error) {
+	if host == "slow.test.com" {
+		<-ctx.Done()
+		return nil, &net.DNSError{Err: "i/o timeout", Name: host, IsTimeout: true}
+	}
+
+	ips, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	if ips == nil {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: host, IsTemporary: true}
+	}
+
+	addrs := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, netip.MustParseAddr(ip))
+	}
+	return addrs, nil
+}
+
+var resolver = fakeResolver{
+	"test.com":           {"93.184.216.34", "2606:2800:220:1::1"},
+	"intranet.test.com":  {"10.1.2.3"},
+	"mixed.test.com":     {"93.184.216.34", "127.0.0.1"},
+	"mapped.test.com":    {"::ffff:192.168.1.1"},
+	"metadata.test.com":  {"169.254.169.254"},
+	"cgnat.test.com":     {"100.64.0.1"},
+	"partner.test.com":   {"203.0.113.7"},
+	"servfail.test.com":  nil,
+	"bit.ly.test.com":    {"93.184.216.34"},
+	"printer.local":      {"192.168.1.50"},
+	"sho.rt":             {"93.184.216.35"},
+	"xn--bcher-kva.test": {"93.184.216.36"},
+}
+
+func newPolicy(t *testing.T, cfg config.DestinationConfig) *destination.Policy {
+	t.Helper()
+
+	p, err := destination.NewPolicy(cfg, "https://sho.rt", resolver)
+	require.NoError(t, err)
+	return p
+}
+
+func TestPolicy_Check(t *testing.T) {
+	t.Parallel()
+
+	p := newPolicy(t, config.DestinationConfig{
+		DeniedDomains:  "bit.ly, bücher.test",
+		DeniedNetworks: "203.0.113.0/24",
+		Resolve:        true,
+	})
+
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://test.com/", nil},
+		{"http://93.184.216.34/", nil},
+		{"https://bit.ly.test.com/", nil},
+		{"https://intranet.test.com/", model.ErrDestinationDenied},
+		{"https://mixed.test.com/", model.ErrDestinationDenied},
+		{"https://mapped.test.com/", model.ErrDestinationDenied},
+		{"https://metadata.test.com/", model.ErrDestinationDenied},
+		{"https://cgnat.test.com/", model.ErrDestinationDenied},
+		{"https://partner.test.com/", model.ErrDestinationDenied},
+		{"http://127.0.0.1:8080/", model.ErrDestinationDenied},
+		{"http://[::1]/", model.ErrDestinationDenied},
+		{"http://[fe80::1]/", model.ErrDestinationDenied},
+		{"http://[::7f00:1]/", model.ErrDestinationDenied},
+		{"http://[64:ff9b::a00:1]/", model.ErrDestinationDenied},
+		{"http://[2002:c0a8:101::1]/", model.ErrDestinationDenied},
+		{"http://[2001:0:4136:e378:8000:63bf:3fff:fdd2]/", model.ErrDestinationDenied},
+		{"http://[64:ff9b::5db8:d822]/", nil},
+		{"http://[2002:cb00:7109::1]/", model.ErrDestinationDenied},
+		{"http://0.0.0.0/", model.ErrDestinationDenied},
+		{"http://192.168.0.10/", model.ErrDestinationDenied},
+		{"https://sho.rt/abc", model.ErrDestinationDenied},
+		{"https://www.sho.rt/abc", model.ErrDestinationDenied},
+		{"https://bit.ly/abc", model.ErrDestinationDenied},
+		{"https://m.bit.ly/abc", model.ErrDestinationDenied},
+		{"https://xn--bcher-kva.test/", model.ErrDestinationDenied},
+		{"https://printer.local/", model.ErrDestinationDenied},
+		{"https://app.localhost/", model.ErrDestinationDenied},
+		{"https://unknown.test.com/", model.ErrInvalidURL},
+	}
+
+	for _, tt := range tests {
+		err := p.Check(context.Background(), tt.url)
+		if tt.want == nil {
+			require.NoError(t, err, tt.url)
+			continue
+		}
+		require.ErrorIs(t, err, tt.want, tt.url)
+	}
+}
+
+func TestPolicy_CheckResolverFailure(t *testing.T) {
+	t.Parallel()
+
+	p := newPolicy(t, config.DestinationConfig{Resolve: true, ResolveTimeout: 10 * time.Millisecond})
+
+	for _, host := range []string{"servfail.test.com", "slow.test.com"} {
+		err := p.Check(context.Background(), "https://"+host+"/")
+		require.ErrorIs(t, err, model.ErrDestinationUnresolved, host)
+
+		var vErr *model.ValidationError
+		require.False(t, errors.As(err, &vErr), "a resolver outage is not the client's fault")
+	}
+}
+
+func TestPolicy_AllowPrivate(t *testing.T) {
+	t.Parallel()
+
+	p := newPolicy(t, config.DestinationConfig{AllowPrivate: true, Resolve: true})
+
+	require.NoError(t, p.Check(context.Background(), "https://intranet.test.com/"))
+	require.NoError(t, p.Check(context.Background(), "http://127.0.0.1/"))
+	require.NoError(t, p.Check(context.Background(), "https://printer.local/"))
+	require.ErrorIs(t, p.Check(context.Background(), "https://sho.rt/"), model.ErrDestinationDenied)
+}
+
+func TestPolicy_WithoutResolving(t *testing.T) {
+	t.Parallel()
+
+	p := newPolicy(t, config.DestinationConfig{})
+
+	require.NoError(t, p.Check(context.Background(), "https://intranet.test.com/"))
+	require.ErrorIs(t, p.Check(context.Background(), "http://10.0.0.1/"), model.ErrDestinationDenied)
+}
+
+func TestNewPolicy_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := destination.NewPolicy(config.DestinationConfig{DeniedNetworks: "not-a-cidr"}, "https://sho.rt", nil)
+	require.Error(t, err)
+
+	_, err = destination.NewPolicy(config.DestinationConfig{DeniedDomains: "bad domain.com"}, "https://sho.rt", nil)
+	require.Error(t, err)
+
+	_, err = destination.NewPolicy(config.DestinationConfig{}, "not a url", nil)
+	require.Error(t, err)
+}
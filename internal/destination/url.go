@@ -0,0 +1,234 @@
+package destination
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/domovonok/url-shortener/internal/domainset"
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+// MaxURLLength bounds destinations to what browsers reliably accept.
+const MaxURLLength = 2048
+
+const field = "url"
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalize validates a destination URL and returns its canonical form, so
+// that trivially different spellings of one URL are stored as the same link.
+//
+// The scheme and host are lowercased, internationalized hosts are converted
+// to punycode, numeric hosts are read the way browsers read them and written
+// as dotted decimal, and default ports are dropped. In the path, escaped
+// unreserved characters are decoded, the remaining escapes uppercased and dot
+// segments removed. Query and fragment are kept as given.
+func Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case raw == "":
+		return "", invalid("is required")
+	case len(raw) > MaxURLLength:
+		return "", invalid("must be at most " + strconv.Itoa(MaxURLLength) + " characters")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", invalid("is not a valid URL")
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if _, ok := defaultPorts[u.Scheme]; !ok {
+		return "", invalid("must use the http or https scheme")
+	}
+	if u.Opaque != "" {
+		return "", invalid("is not a valid URL")
+	}
+	// Credentials in the authority are a classic way to disguise the real
+	// host, as in https://bank.example@evil.example.
+	if u.User != nil {
+		return "", invalid("must not contain credentials")
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return "", err
+	}
+	port := u.Port()
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return "", invalid("has an invalid port")
+		}
+	}
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	escaped := normalizeEscapes(u.EscapedPath())
+	u.Path, _ = url.PathUnescape(escaped)
+	u.RawPath = escaped
+
+	// Resolving against an empty reference removes dot segments from the
+	// escaped path and keeps query and fragment.
+	u = u.ResolveReference(&url.URL{})
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	res := u.String()
+	if len(res) > MaxURLLength {
+		return "", invalid("must be at most " + strconv.Itoa(MaxURLLength) + " characters")
+	}
+	return res, nil
+}
+
+func normalizeHost(host string) (string, error) {
+	if host == "" {
+		return "", invalid("must have a host")
+	}
+	if strings.Contains(host, ":") {
+		if ip := net.ParseIP(host); ip != nil {
+			return ip.String(), nil
+		}
+		return "", invalid("has an invalid IP address")
+	}
+	// Browsers read hosts like 127.1, 0x7f.0.0.1 or 0177.0.0.1 as IPv4
+	// addresses, so such a host must not slip through as a domain name.
+	if endsInNumber(host) {
+		ip, ok := parseIPv4(host)
+		if !ok {
+			return "", invalid("has an invalid IP address")
+		}
+		return ip.String(), nil
+	}
+
+	// Hosts are read as browsers read them, so some that are not valid DNS
+	// names, such as my_host.example.com, are accepted.
+	ascii, err := domainset.ToASCII(strings.TrimSuffix(host, "."))
+	if err != nil || !strings.Contains(ascii, ".") {
+		return "", invalid("must have a valid domain name")
+	}
+	return ascii, nil
+}
+
+// endsInNumber reports whether the last label of host is numeric, which
+// makes it an IPv4 address in the WHATWG URL standard.
+func endsInNumber(host string) bool {
+	labels := strings.Split(host, ".")
+	if len(labels) > 1 && labels[len(labels)-1] == "" {
+		labels = labels[:len(labels)-1]
+	}
+	last := labels[len(labels)-1]
+	if last != "" && strings.Trim(last, "0123456789") == "" {
+		return true
+	}
+	_, ok := parseIPv4Number(last)
+	return ok
+}
+
+// parseIPv4 parses host with the WHATWG IPv4 parser: up to four decimal,
+// octal (leading 0) or hexadecimal (0x) numbers, the last of which fills
+// the remaining bytes.
+func parseIPv4(host string) (netip.Addr, bool) {
+	parts := strings.Split(host, ".")
+	if len(parts) > 1 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) > 4 {
+		return netip.Addr{}, false
+	}
+
+	var ipv4 uint64
+	for i, part := range parts {
+		n, ok := parseIPv4Number(part)
+		if !ok {
+			return netip.Addr{}, false
+		}
+		if i < len(parts)-1 {
+			if n > 255 {
+				return netip.Addr{}, false
+			}
+			ipv4 |= n << (8 * (3 - i))
+			continue
+		}
+		if n >= 1<<(8*(5-len(parts))) {
+			return netip.Addr{}, false
+		}
+		ipv4 |= n
+	}
+
+	return netip.AddrFrom4([4]byte{byte(ipv4 >> 24), byte(ipv4 >> 16), byte(ipv4 >> 8), byte(ipv4)}), true
+}
+
+func parseIPv4Number(s string) (uint64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	base := 10
+	switch {
+	case len(s) >= 2 && (s[:2] == "0x" || s[:2] == "0X"):
+		s, base = s[2:], 16
+	case len(s) >= 2 && s[0] == '0':
+		s, base = s[1:], 8
+	}
+	if s == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseUint(s, base, 32)
+	return n, err == nil
+}
+
+// normalizeEscapes decodes percent-encoded unreserved characters, which are
+// equivalent to their literal form, and uppercases the hex digits of all
+// other escapes. The input must be validly escaped.
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		c, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			b.WriteByte(s[i])
+			continue
+		}
+		if unreserved(byte(c)) {
+			b.WriteByte(byte(c))
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xf])
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+func unreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func invalid(reason string) error {
+	return model.NewValidationError(model.ErrInvalidURL, field, reason)
+}
@@ -0,0 +1,59 @@
+package domainset
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Set matches domains together with all of their subdomains. Lookups walk up
+// the labels of a host, so they cost one map access per label regardless of
+// the size of the set.
+type Set map[string]struct{}
+
+// profile maps names the way browsers do: UTS #46 without the hyphen and
+// STD3 rules, which real hosts like r3---sn-4g5e6nsz.googlevideo.com or
+// my_host.example.com do not follow.
+var profile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.CheckHyphens(false), idna.StrictDomainName(false))
+
+// ToASCII converts a domain to lowercase ASCII with internationalized labels
+// in punycode. Labels must be non-empty and hold only letters, digits, '-'
+// and '_'.
+func ToASCII(domain string) (string, error) {
+	ascii, err := profile.ToASCII(domain)
+	if err != nil || ascii == "" {
+		return "", fmt.Errorf("invalid domain %q", domain)
+	}
+	for _, label := range strings.Split(ascii, ".") {
+		if label == "" || strings.Trim(label, "abcdefghijklmnopqrstuvwxyz0123456789-_") != "" {
+			return "", fmt.Errorf("invalid domain %q", domain)
+		}
+	}
+	return ascii, nil
+}
+
+// Add inserts a domain, converting internationalized names to punycode.
+func (s Set) Add(domain string) error {
+	ascii, err := ToASCII(strings.Trim(strings.TrimSpace(domain), "."))
+	if err != nil {
+		return err
+	}
+	s[ascii] = struct{}{}
+	return nil
+}
+
+// Match returns the entry covering host, which must already be lowercase
+// ASCII without a trailing dot.
+func (s Set) Match(host string) (string, bool) {
+	for {
+		if _, ok := s[host]; ok {
+			return host, true
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			return "", false
+		}
+		host = host[i+1:]
+	}
+}
@@ -0,0 +1,45 @@
+package domainset_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/domainset"
+)
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	s := domainset.Set{}
+	require.NoError(t, s.Add("evil.example"))
+	require.NoError(t, s.Add(" Phish.TEST. "))
+	require.NoError(t, s.Add("bücher.example"))
+	require.NoError(t, s.Add("r3---sn-4g5e6nsz.googlevideo.com"))
+	require.NoError(t, s.Add("my_host.example"))
+	require.Error(t, s.Add("bad domain.example"))
+	require.Error(t, s.Add("evil.example/path"))
+	require.Error(t, s.Add("a..example"))
+	require.Error(t, s.Add(""))
+
+	tests := []struct {
+		host string
+		rule string
+	}{
+		{"evil.example", "evil.example"},
+		{"www.evil.example", "evil.example"},
+		{"a.b.evil.example", "evil.example"},
+		{"phish.test", "phish.test"},
+		{"xn--bcher-kva.example", "xn--bcher-kva.example"},
+		{"cdn.my_host.example", "my_host.example"},
+		{"notevil.example", ""},
+		{"evil.example.com", ""},
+		{"example", ""},
+	}
+
+	for _, tt := range tests {
+		rule, ok := s.Match(tt.host)
+		require.Equal(t, tt.rule != "", ok, tt.host)
+		require.Equal(t, tt.rule, rule, tt.host)
+	}
+}
@@ -0,0 +1,206 @@
+package ipfilter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/domovonok/url-shortener/internal/clientip"
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/logger"
+)
+
+const (
+	GroupCreate  = "create"
+	GroupAPI     = "api"
+	GroupMetrics = "metrics"
+
+	actionAllow = "allow"
+	actionDeny  = "deny"
+)
+
+// Lists holds the allow and deny rules of a route group.
+type Lists struct {
+	Allow []netip.Prefix
+	Deny  []netip.Prefix
+}
+
+// Permits reports whether addr may pass: it must not be denied and, if there
+// is an allowlist, it must be on it.
+func (l Lists) Permits(addr netip.Addr) bool {
+	if contains(l.Deny, addr) {
+		return false
+	}
+	return len(l.Allow) == 0 || contains(l.Allow, addr)
+}
+
+// Filter enforces per route group lists assembled from the config and an
+// optional rules file, which is reloaded whenever its content changes.
+type Filter struct {
+	static     map[string]Lists
+	path       string
+	interval   time.Duration
+	sum        [sha256.Size]byte
+	unreadable bool
+	lists      atomic.Pointer[map[string]Lists]
+	log        logger.Logger
+}
+
+func New(cfg config.IPFilterConfig, log logger.Logger) (*Filter, error) {
+	static := make(map[string]Lists, len(cfg.Groups))
+	for group, g := range cfg.Groups {
+		allow, err := clientip.ParsePrefixes(g.Allow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s allowlist: %w", group, err)
+		}
+		deny, err := clientip.ParsePrefixes(g.Deny)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s denylist: %w", group, err)
+		}
+		static[group] = Lists{Allow: allow, Deny: deny}
+	}
+
+	f := &Filter{
+		static:   static,
+		path:     cfg.File,
+		interval: cfg.ReloadInterval,
+		log:      log,
+	}
+	f.lists.Store(&static)
+
+	if f.path != "" {
+		if _, err := f.reload(); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// Allowed reports whether addr may reach the routes of group.
+func (f *Filter) Allowed(group string, addr netip.Addr) bool {
+	return (*f.lists.Load())[group].Permits(addr)
+}
+
+// Run reloads the rules file on change until ctx is cancelled. A file that
+// fails to parse leaves the previous rules in place, while a file that can no
+// longer be read leaves only the configured lists. Either is logged once.
+func (f *Filter) Run(ctx context.Context) {
+	if f.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := f.reload()
+			if err != nil {
+				f.log.Error("Unable to reload IP filter rules", logger.Any("file", f.path), logger.Error(err))
+			} else if reloaded {
+				f.log.Info("IP filter rules reloaded", logger.Any("file", f.path))
+			}
+		}
+	}
+}
+
+// reload applies the rules file if its content changed and reports whether
+// the rules did. Errors are only returned for a change of content or of the
+// file's readability, so that a broken file is not reported on every tick.
+func (f *Filter) reload() (bool, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if f.unreadable {
+			return false, nil
+		}
+		f.unreadable = true
+		f.sum = [sha256.Size]byte{}
+		f.lists.Store(&f.static)
+		return true, fmt.Errorf("falling back to the configured lists: %w", err)
+	}
+	f.unreadable = false
+
+	sum := sha256.Sum256(data)
+	if sum == f.sum {
+		return false, nil
+	}
+	f.sum = sum
+
+	fromFile, err := parse(f.path, data)
+	if err != nil {
+		return false, err
+	}
+
+	lists := make(map[string]Lists, len(f.static)+len(fromFile))
+	for group, l := range f.static {
+		lists[group] = l
+	}
+	for group, l := range fromFile {
+		merged := lists[group]
+		merged.Allow = append(merged.Allow[:len(merged.Allow):len(merged.Allow)], l.Allow...)
+		merged.Deny = append(merged.Deny[:len(merged.Deny):len(merged.Deny)], l.Deny...)
+		lists[group] = merged
+	}
+
+	f.lists.Store(&lists)
+
+	return true, nil
+}
+
+// parse reads rules of the form "<group> <allow|deny> <cidr>", one per line,
+// from the content of the file at path. Blank lines and lines starting with #
+// are ignored.
+func parse(path string, data []byte) (map[string]Lists, error) {
+	lists := make(map[string]Lists)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected <group> <allow|deny> <cidr>", path, n)
+		}
+		group, action := fields[0], fields[1]
+
+		prefixes, err := clientip.ParsePrefixes(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+
+		l := lists[group]
+		switch action {
+		case actionAllow:
+			l.Allow = append(l.Allow, prefixes...)
+		case actionDeny:
+			l.Deny = append(l.Deny, prefixes...)
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown action %q", path, n, action)
+		}
+		lists[group] = l
+	}
+
+	return lists, scanner.Err()
+}
+
+func contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
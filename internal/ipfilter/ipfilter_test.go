@@ -0,0 +1,81 @@
+package ipfilter_test
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/ipfilter"
+	"github.com/domovonok/url-shortener/internal/logger"
+)
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "rules")
+	require.NoError(t, os.WriteFile(path, []byte("# office\ncreate allow 198.51.100.0/24\n"), 0o600))
+
+	f, err := ipfilter.New(config.IPFilterConfig{
+		Groups: map[string]config.IPListConfig{
+			ipfilter.GroupCreate: {Allow: "10.0.0.0/8", Deny: "10.0.0.13"},
+			ipfilter.GroupAPI:    {Deny: "203.0.113.0/24"},
+		},
+		File:           path,
+		ReloadInterval: 10 * time.Millisecond,
+	}, logger.MustInit(false))
+	require.NoError(t, err)
+
+	addr := netip.MustParseAddr
+	require.True(t, f.Allowed(ipfilter.GroupCreate, addr("10.1.2.3")))
+	require.True(t, f.Allowed(ipfilter.GroupCreate, addr("198.51.100.7")), "file rules extend the config")
+	require.False(t, f.Allowed(ipfilter.GroupCreate, addr("10.0.0.13")), "deny wins over allow")
+	require.False(t, f.Allowed(ipfilter.GroupCreate, addr("192.0.2.1")))
+	require.False(t, f.Allowed(ipfilter.GroupCreate, netip.Addr{}))
+	require.True(t, f.Allowed(ipfilter.GroupAPI, addr("192.0.2.1")))
+	require.False(t, f.Allowed(ipfilter.GroupAPI, addr("203.0.113.9")))
+	require.True(t, f.Allowed("unfiltered", addr("203.0.113.9")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go f.Run(ctx)
+
+	// A rewrite is noticed even if it keeps the modification time.
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("create allow 192.0.2.0/24\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+
+	require.Eventually(t, func() bool {
+		return f.Allowed(ipfilter.GroupCreate, addr("192.0.2.1"))
+	}, time.Second, 10*time.Millisecond)
+	require.False(t, f.Allowed(ipfilter.GroupCreate, addr("198.51.100.7")))
+
+	// Without the file only the configured lists apply.
+	require.NoError(t, os.Remove(path))
+	require.Eventually(t, func() bool {
+		return !f.Allowed(ipfilter.GroupCreate, addr("192.0.2.1"))
+	}, time.Second, 10*time.Millisecond)
+	require.True(t, f.Allowed(ipfilter.GroupCreate, addr("10.1.2.3")))
+
+	// The same rules come back with the file.
+	require.NoError(t, os.WriteFile(path, []byte("create allow 192.0.2.0/24\n"), 0o600))
+	require.Eventually(t, func() bool {
+		return f.Allowed(ipfilter.GroupCreate, addr("192.0.2.1"))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNewRejectsMalformedFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "rules")
+	require.NoError(t, os.WriteFile(path, []byte("create permit 10.0.0.0/8\n"), 0o600))
+
+	_, err := ipfilter.New(config.IPFilterConfig{File: path}, logger.MustInit(false))
+	require.ErrorContains(t, err, "unknown action")
+}
@@ -0,0 +1,111 @@
+package limiter
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/domovonok/url-shortener/internal/config"
+)
+
+const (
+	TierIP  = "ip"
+	TierKey = "key"
+
+	BackendLocal = "local"
+	BackendRedis = "redis"
+
+	PolicyRedirect = "redirect"
+	PolicyCreate   = "create"
+	PolicyAPI      = "api"
+	// PolicyAuth throttles failed authentication attempts per client IP.
+	PolicyAuth = "auth"
+)
+
+var errFallback = errors.New("rate limiter in fallback mode")
+
+// Key builds a limiter key for a client identified by id within a tier.
+func Key(tier, id string) string {
+	return tier + ":" + id
+}
+
+// Keyed keeps a separate token bucket per client key. The limits of a bucket
+// are taken from the tier the key belongs to; unknown tiers get the IP tier.
+type Keyed struct {
+	tiers       map[string]config.BucketConfig
+	idleTimeout time.Duration
+	buckets     map[string]*keyedBucket
+	lastSweep   time.Time
+	mu          sync.Mutex
+}
+
+type keyedBucket struct {
+	*TokenBucket
+	lastSeen time.Time
+}
+
+func NewKeyed(p config.RateLimitPolicy, idleTimeout time.Duration) *Keyed {
+	return &Keyed{
+		tiers:       p.Tiers,
+		idleTimeout: idleTimeout,
+		buckets:     make(map[string]*keyedBucket),
+		lastSweep:   time.Now(),
+	}
+}
+
+func (k *Keyed) Take(key string) Decision {
+	return k.bucket(key).Take()
+}
+
+func (k *Keyed) Peek(key string) Decision {
+	return k.bucket(key).Peek()
+}
+
+func (k *Keyed) Capacity(key string) int {
+	return tierOf(k.tiers, key).Capacity
+}
+
+func (k *Keyed) RefillRate(key string) int {
+	return tierOf(k.tiers, key).RefillRate
+}
+
+func (k *Keyed) bucket(key string) *TokenBucket {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	k.sweep(now)
+
+	b, ok := k.buckets[key]
+	if !ok {
+		b = &keyedBucket{TokenBucket: NewTokenBucket(tierOf(k.tiers, key))}
+		k.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	return b.TokenBucket
+}
+
+// sweep drops buckets that have been idle for longer than the idle timeout.
+// With a timeout at least as long as a full refill, a dropped bucket would
+// have been full anyway, so eviction does not change any client's limit.
+func (k *Keyed) sweep(now time.Time) {
+	if now.Sub(k.lastSweep) < k.idleTimeout {
+		return
+	}
+	for key, b := range k.buckets {
+		if now.Sub(b.lastSeen) >= k.idleTimeout {
+			delete(k.buckets, key)
+		}
+	}
+	k.lastSweep = now
+}
+
+func tierOf(tiers map[string]config.BucketConfig, key string) config.BucketConfig {
+	tier, _, _ := strings.Cut(key, ":")
+	if cfg, ok := tiers[tier]; ok {
+		return cfg
+	}
+	return tiers[TierIP]
+}
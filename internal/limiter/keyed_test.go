@@ -0,0 +1,51 @@
+package limiter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/limiter"
+)
+
+func TestKeyed(t *testing.T) {
+	t.Parallel()
+
+	l := limiter.NewKeyed(config.RateLimitPolicy{
+		Tiers: map[string]config.BucketConfig{
+			limiter.TierIP:  {Capacity: 1},
+			limiter.TierKey: {Capacity: 2},
+		},
+	}, time.Minute)
+
+	first := limiter.Key(limiter.TierIP, "192.0.2.1")
+	second := limiter.Key(limiter.TierIP, "192.0.2.2")
+	owner := limiter.Key(limiter.TierKey, "42")
+
+	require.True(t, l.Take(first).Allowed)
+	require.False(t, l.Take(first).Allowed)
+	require.True(t, l.Take(second).Allowed, "clients must not share a bucket")
+
+	require.Equal(t, 2, l.Capacity(owner))
+	require.True(t, l.Take(owner).Allowed)
+	require.True(t, l.Take(owner).Allowed)
+	require.False(t, l.Take(owner).Allowed)
+	require.Equal(t, 0, l.Peek(owner).Remaining)
+}
+
+func TestKeyedEvictsIdleBuckets(t *testing.T) {
+	t.Parallel()
+
+	l := limiter.NewKeyed(config.RateLimitPolicy{
+		Tiers: map[string]config.BucketConfig{limiter.TierIP: {Capacity: 1}},
+	}, 10*time.Millisecond)
+
+	key := limiter.Key(limiter.TierIP, "192.0.2.1")
+	require.True(t, l.Take(key).Allowed)
+	require.False(t, l.Take(key).Allowed)
+
+	time.Sleep(20 * time.Millisecond)
+	require.True(t, l.Take(key).Allowed)
+}
@@ -7,6 +7,15 @@ import (
 	"github.com/domovonok/url-shortener/internal/config"
 )
 
+// Decision is the outcome of taking a token from a bucket.
+type Decision struct {
+	Allowed bool
+	// Remaining is the number of tokens left after the request.
+	Remaining int
+	// RetryAfter is the time until the next token, or 0 if one is left.
+	RetryAfter time.Duration
+}
+
 type TokenBucket struct {
 	capacity   int
 	tokens     int
@@ -15,7 +24,7 @@ type TokenBucket struct {
 	mu         sync.Mutex
 }
 
-func NewTokenBucket(cfg config.RateLimitConfig) *TokenBucket {
+func NewTokenBucket(cfg config.BucketConfig) *TokenBucket {
 	return &TokenBucket{
 		capacity:   cfg.Capacity,
 		tokens:     cfg.Capacity,
@@ -25,17 +34,31 @@ func NewTokenBucket(cfg config.RateLimitConfig) *TokenBucket {
 }
 
 func (tb *TokenBucket) Allow() bool {
+	return tb.Take().Allowed
+}
+
+// Take takes a token if one is available and reports the bucket's state
+// afterwards.
+func (tb *TokenBucket) Take() Decision {
+	return tb.take(1)
+}
+
+// Peek reports whether a request would be allowed without taking a token.
+func (tb *TokenBucket) Peek() Decision {
+	return tb.take(0)
+}
+
+func (tb *TokenBucket) take(cost int) Decision {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 
 	tb.refill()
 
-	if tb.tokens > 0 {
-		tb.tokens--
-		return true
+	allowed := tb.tokens > 0
+	if allowed {
+		tb.tokens -= cost
 	}
-
-	return false
+	return Decision{Allowed: allowed, Remaining: tb.tokens, RetryAfter: tb.nextToken()}
 }
 
 func (tb *TokenBucket) Remaining() int {
@@ -54,6 +77,26 @@ func (tb *TokenBucket) RefillRate() int {
 	return tb.refillRate
 }
 
+// NextToken reports how long it takes until a token is available, or 0 if
+// one is available now. A bucket that never refills reports 0 as well.
+func (tb *TokenBucket) NextToken() time.Duration {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.refill()
+	return tb.nextToken()
+}
+
+func (tb *TokenBucket) nextToken() time.Duration {
+	if tb.tokens > 0 || tb.refillRate <= 0 {
+		return 0
+	}
+
+	// refill adds a token once this many whole milliseconds have passed.
+	perToken := time.Duration((1000+tb.refillRate-1)/tb.refillRate) * time.Millisecond
+	return max(perToken-time.Since(tb.lastRefill), 0)
+}
+
 func (tb *TokenBucket) refill() {
 	now := time.Now()
 	elapsed := now.Sub(tb.lastRefill)
@@ -0,0 +1,26 @@
+package limiter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/limiter"
+)
+
+func TestTokenBucketNextToken(t *testing.T) {
+	t.Parallel()
+
+	tb := limiter.NewTokenBucket(config.BucketConfig{Capacity: 1, RefillRate: 4})
+	require.Zero(t, tb.NextToken())
+
+	require.True(t, tb.Allow())
+	wait := tb.NextToken()
+	require.Positive(t, wait)
+	require.LessOrEqual(t, wait, 250*time.Millisecond)
+
+	time.Sleep(wait)
+	require.True(t, tb.Allow())
+}
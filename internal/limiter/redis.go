@@ -0,0 +1,134 @@
+package limiter
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/logger"
+)
+
+const (
+	redisKeyPrefix = "ratelimit:"
+
+	// fallbackPeriod is how long the local buckets are used after Redis fails
+	// before it is tried again.
+	fallbackPeriod = 5 * time.Second
+)
+
+// tokenBucketScript refills and takes from a bucket stored as a hash in one
+// atomic step. Time is taken from the Redis server so that replicas with
+// skewed clocks agree. A cost of 0 only reports the bucket's state and leaves
+// it untouched. The reply is whether the request is allowed, the remaining
+// tokens and the number of milliseconds until the next token.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+if tokens >= math.max(cost, 1) then
+	tokens = tokens - cost
+	allowed = 1
+end
+
+if cost > 0 then
+	redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+	if rate > 0 then
+		redis.call('PEXPIRE', KEYS[1], math.ceil(capacity * 1000 / rate) + 1000)
+	end
+end
+
+local wait = 0
+if tokens < 1 and rate > 0 then
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+return {allowed, math.floor(tokens), wait}
+`)
+
+// Redis keeps token buckets in Redis so that every replica draws from the
+// same bucket per key. While Redis is unavailable it falls back to the local
+// keyed buckets.
+type Redis struct {
+	c          *redis.Client
+	policy     string
+	tiers      map[string]config.BucketConfig
+	timeout    time.Duration
+	fallback   *Keyed
+	fallbackTo atomic.Int64
+	log        logger.Logger
+}
+
+func NewRedis(c *redis.Client, policy string, p config.RateLimitPolicy, cfg config.RateLimitConfig, log logger.Logger) *Redis {
+	return &Redis{
+		c:        c,
+		policy:   policy,
+		tiers:    p.Tiers,
+		timeout:  cfg.RedisTimeout,
+		fallback: NewKeyed(p, cfg.IdleTimeout),
+		log:      log,
+	}
+}
+
+func (l *Redis) Take(key string) Decision {
+	d, err := l.take(key, 1)
+	if err != nil {
+		return l.fallback.Take(key)
+	}
+	return d
+}
+
+func (l *Redis) Peek(key string) Decision {
+	d, err := l.take(key, 0)
+	if err != nil {
+		return l.fallback.Peek(key)
+	}
+	return d
+}
+
+func (l *Redis) Capacity(key string) int {
+	return tierOf(l.tiers, key).Capacity
+}
+
+func (l *Redis) RefillRate(key string) int {
+	return tierOf(l.tiers, key).RefillRate
+}
+
+func (l *Redis) take(key string, cost int) (Decision, error) {
+	if time.Now().UnixNano() < l.fallbackTo.Load() {
+		return Decision{}, errFallback
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+
+	tier := tierOf(l.tiers, key)
+	res, err := tokenBucketScript.Run(ctx, l.c, []string{redisKeyPrefix + l.policy + ":" + key}, tier.Capacity, tier.RefillRate, cost).Int64Slice()
+	if err != nil {
+		l.fallbackTo.Store(time.Now().Add(fallbackPeriod).UnixNano())
+		l.log.Warn("Redis rate limiter unavailable, using local buckets",
+			logger.Any("policy", l.policy),
+			logger.Any("retry_in", fallbackPeriod),
+			logger.Error(err),
+		)
+		return Decision{}, err
+	}
+
+	return Decision{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package limiter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/limiter"
+	"github.com/domovonok/url-shortener/internal/logger"
+)
+
+func TestRedisFallsBackToLocalBuckets(t *testing.T) {
+	t.Parallel()
+
+	// Nothing listens on the discard port, so every script call fails.
+	c := redis.NewClient(&redis.Options{Addr: "127.0.0.1:9", MaxRetries: -1})
+	t.Cleanup(func() { _ = c.Close() })
+
+	l := limiter.NewRedis(c, limiter.PolicyRedirect, config.RateLimitPolicy{
+		Tiers: map[string]config.BucketConfig{limiter.TierIP: {Capacity: 2}},
+	}, config.RateLimitConfig{
+		IdleTimeout:  time.Minute,
+		RedisTimeout: 100 * time.Millisecond,
+	}, logger.MustInit(false))
+
+	key := limiter.Key(limiter.TierIP, "192.0.2.1")
+	require.Equal(t, 2, l.Capacity(key))
+	require.True(t, l.Take(key).Allowed)
+	require.True(t, l.Take(key).Allowed)
+	require.False(t, l.Take(key).Allowed)
+	require.Equal(t, 0, l.Peek(key).Remaining)
+}
+
+func TestRedisTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	s := miniredis.RunT(t)
+	c := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	t.Cleanup(func() { _ = c.Close() })
+
+	l := limiter.NewRedis(c, limiter.PolicyRedirect, config.RateLimitPolicy{
+		Tiers: map[string]config.BucketConfig{limiter.TierIP: {Capacity: 2, RefillRate: 1}},
+	}, config.RateLimitConfig{
+		IdleTimeout:  time.Minute,
+		RedisTimeout: time.Second,
+	}, logger.MustInit(false))
+
+	key := limiter.Key(limiter.TierIP, "192.0.2.1")
+	now := time.Now()
+	s.SetTime(now)
+
+	require.Equal(t, limiter.Decision{Allowed: true, Remaining: 2}, l.Peek(key))
+	require.False(t, s.Exists("ratelimit:redirect:"+key), "peeking must not create the bucket")
+
+	require.Equal(t, limiter.Decision{Allowed: true, Remaining: 1}, l.Take(key))
+	require.Equal(t, limiter.Decision{Allowed: true, Remaining: 0, RetryAfter: time.Second}, l.Take(key))
+	require.Equal(t, limiter.Decision{Allowed: false, Remaining: 0, RetryAfter: time.Second}, l.Take(key))
+
+	// Tokens are refilled in proportion to the time passed on the server.
+	s.SetTime(now.Add(500 * time.Millisecond))
+	require.Equal(t, limiter.Decision{Allowed: false, Remaining: 0, RetryAfter: 500 * time.Millisecond}, l.Peek(key))
+
+	s.SetTime(now.Add(1500 * time.Millisecond))
+	require.Equal(t, limiter.Decision{Allowed: true, Remaining: 0, RetryAfter: 500 * time.Millisecond}, l.Take(key))
+
+	s.SetTime(now.Add(time.Hour))
+	require.Equal(t, limiter.Decision{Allowed: true, Remaining: 2}, l.Peek(key))
+}
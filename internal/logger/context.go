@@ -0,0 +1,21 @@
+package logger
+
+import "context"
+
+type fieldsKey struct{}
+
+// ContextWithFields returns a context carrying fields in addition to those
+// already attached to ctx. Loggers obtained through WithContext include them
+// in every entry.
+func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
+	existing := FieldsFromContext(ctx)
+	merged := make([]Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+func FieldsFromContext(ctx context.Context) []Field {
+	fields, _ := ctx.Value(fieldsKey{}).([]Field)
+	return fields
+}
@@ -1,5 +1,7 @@
 package logger
 
+import "context"
+
 type Logger interface {
 	Info(msg string, fields ...Field)
 	Error(msg string, fields ...Field)
@@ -7,6 +9,10 @@ type Logger interface {
 	Warn(msg string, fields ...Field)
 	Fatal(msg string, fields ...Field)
 	Sync() error
+	// With returns a logger that adds fields to every entry.
+	With(fields ...Field) Logger
+	// WithContext returns a logger that adds the fields carried by ctx.
+	WithContext(ctx context.Context) Logger
 }
 
 type Field struct {
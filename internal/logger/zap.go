@@ -1,9 +1,17 @@
 package logger
 
-import "go.uber.org/zap"
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
 
 type ZapLogger struct {
 	logger *zap.Logger
+	// fields are taken from a context and only converted for entries that
+	// are actually written.
+	fields []Field
 }
 
 func MustInit(debug bool) *ZapLogger {
@@ -17,33 +25,62 @@ func MustInit(debug bool) *ZapLogger {
 }
 
 func (z *ZapLogger) Info(msg string, fields ...Field) {
-	z.logger.Info(msg, toZapFields(fields)...)
+	z.write(zapcore.InfoLevel, msg, fields)
 }
 
 func (z *ZapLogger) Error(msg string, fields ...Field) {
-	z.logger.Error(msg, toZapFields(fields)...)
+	z.write(zapcore.ErrorLevel, msg, fields)
 }
 
 func (z *ZapLogger) Debug(msg string, fields ...Field) {
-	z.logger.Debug(msg, toZapFields(fields)...)
+	z.write(zapcore.DebugLevel, msg, fields)
 }
 
 func (z *ZapLogger) Warn(msg string, fields ...Field) {
-	z.logger.Warn(msg, toZapFields(fields)...)
+	z.write(zapcore.WarnLevel, msg, fields)
 }
 
 func (z *ZapLogger) Fatal(msg string, fields ...Field) {
-	z.logger.Fatal(msg, toZapFields(fields)...)
+	z.write(zapcore.FatalLevel, msg, fields)
 }
 
 func (z *ZapLogger) Sync() error {
 	return z.logger.Sync()
 }
 
-func toZapFields(fields []Field) []zap.Field {
-	zapFields := make([]zap.Field, 0, len(fields))
-	for _, f := range fields {
-		zapFields = append(zapFields, zap.Any(f.Key, f.Value))
+func (z *ZapLogger) With(fields ...Field) Logger {
+	if len(fields) == 0 {
+		return z
+	}
+	return &ZapLogger{logger: z.logger.With(toZapFields(fields)...), fields: z.fields}
+}
+
+// WithContext is cheap enough to call for every entry: the context's fields
+// are not encoded unless the entry's level is enabled.
+func (z *ZapLogger) WithContext(ctx context.Context) Logger {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return z
+	}
+	return &ZapLogger{logger: z.logger, fields: append(z.fields[:len(z.fields):len(z.fields)], fields...)}
+}
+
+func (z *ZapLogger) write(level zapcore.Level, msg string, fields []Field) {
+	if ce := z.logger.Check(level, msg); ce != nil {
+		ce.Write(toZapFields(z.fields, fields)...)
+	}
+}
+
+func toZapFields(sets ...[]Field) []zap.Field {
+	var n int
+	for _, fields := range sets {
+		n += len(fields)
+	}
+	zapFields := make([]zap.Field, 0, n)
+	for _, fields := range sets {
+		for _, f := range fields {
+			zapFields = append(zapFields, zap.Any(f.Key, f.Value))
+		}
 	}
 	return zapFields
 }
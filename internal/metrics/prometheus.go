@@ -12,6 +12,27 @@ type PrometheusMetrics struct {
 	SystemMemoryUsage      prometheus.Gauge
 	ApplicationMemoryUsage prometheus.Gauge
 	RateLimitExceededTotal prometheus.Counter
+	RateLimitRequestsTotal *prometheus.CounterVec
+	IPFilterRejectedTotal  *prometheus.CounterVec
+
+	BlocklistHitsTotal *prometheus.CounterVec
+	BlocklistEntries   prometheus.Gauge
+
+	ClickEventsWrittenTotal     prometheus.Counter
+	ClickEventsDroppedTotal     prometheus.Counter
+	ClickEventsWriteErrorsTotal prometheus.Counter
+
+	CacheRequestsTotal *prometheus.CounterVec
+
+	LocalCacheRequestsTotal  *prometheus.CounterVec
+	LocalCacheEvictionsTotal *prometheus.CounterVec
+	LocalCacheEntries        prometheus.Gauge
+	LocalCacheBytes          prometheus.Gauge
+
+	CacheInvalidationLagSeconds  prometheus.Histogram
+	CacheInvalidationErrorsTotal *prometheus.CounterVec
+
+	CacheBreakerState prometheus.Gauge
 }
 
 func NewPrometheusMetrics() *PrometheusMetrics {
@@ -55,5 +76,103 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 				Help: "Total number of rate limit exceeded events",
 			},
 		),
+		RateLimitRequestsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rate_limit_requests_total",
+				Help: "Total number of rate-limited requests by policy and result",
+			},
+			[]string{"policy", "result"},
+		),
+		IPFilterRejectedTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ip_filter_rejected_total",
+				Help: "Total number of requests rejected by IP allow/deny lists by route group",
+			},
+			[]string{"group"},
+		),
+		BlocklistHitsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "blocklist_hits_total",
+				Help: "Total number of destinations matched by the blocklist by stage",
+			},
+			[]string{"stage"},
+		),
+		BlocklistEntries: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "blocklist_entries",
+				Help: "Number of domains and URLs on the loaded blocklist",
+			},
+		),
+		ClickEventsWrittenTotal: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "click_events_written_total",
+				Help: "Total number of click events written to the database",
+			},
+		),
+		ClickEventsDroppedTotal: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "click_events_dropped_total",
+				Help: "Total number of click events dropped because the buffer was full",
+			},
+		),
+		ClickEventsWriteErrorsTotal: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "click_events_write_errors_total",
+				Help: "Total number of failed click event batch writes",
+			},
+		),
+		CacheRequestsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "link_cache_requests_total",
+				Help: "Total number of link cache lookups by result",
+			},
+			[]string{"result"},
+		),
+		LocalCacheRequestsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "local_cache_requests_total",
+				Help: "Total number of in-process cache lookups by result",
+			},
+			[]string{"result"},
+		),
+		LocalCacheEvictionsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "local_cache_evictions_total",
+				Help: "Total number of in-process cache evictions by reason",
+			},
+			[]string{"reason"},
+		),
+		LocalCacheEntries: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "local_cache_entries",
+				Help: "Number of entries in the in-process cache",
+			},
+		),
+		LocalCacheBytes: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "local_cache_bytes",
+				Help: "Approximate size of the in-process cache in bytes",
+			},
+		),
+		CacheInvalidationLagSeconds: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "cache_invalidation_lag_seconds",
+				Help:    "Delay between publishing and applying a cache invalidation",
+				Buckets: prometheus.DefBuckets,
+			},
+		),
+		CacheInvalidationErrorsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cache_invalidation_errors_total",
+				Help: "Total number of cache invalidation failures by stage",
+			},
+			[]string{"stage"},
+		),
+		CacheBreakerState: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "cache_breaker_open",
+				Help: "Whether the Redis circuit breaker is open (1) or closed (0)",
+			},
+		),
 	}
 }
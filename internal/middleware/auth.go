@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/domovonok/url-shortener/internal/auth"
+	"github.com/domovonok/url-shortener/internal/clientip"
+	"github.com/domovonok/url-shortener/internal/limiter"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/transport/http/problem"
+)
+
+type authenticator interface {
+	Authenticate(ctx context.Context, key string) (int64, error)
+}
+
+// Auth resolves the bearer API key of a request to its owner, stores the
+// owner in the request context and rejects requests without a valid key. It
+// is mounted only on routes that need an owner; elsewhere the Authorization
+// header is ignored.
+//
+// Every rejected request takes a token from the client IP's bucket in
+// failures. Once the bucket is empty the client is turned away before its key
+// is looked up, so guessing keys cannot flood the database. A nil failures
+// limiter disables this.
+func Auth(a authenticator, failures rateLimiter, log logger.Logger, m *metrics.PrometheusMetrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ipKey := IPKey(r)
+			// Peeking leaves the bucket alone, so valid keys cost nothing.
+			if d := peek(failures, ipKey); !d.Allowed {
+				m.RateLimitRequestsTotal.WithLabelValues(limiter.PolicyAuth, rateLimitThrottled).Inc()
+				log.WithContext(r.Context()).Warn("Too many failed authentications",
+					logger.Any("method", r.Method),
+					logger.Any("path", r.URL.Path),
+					logger.Any("client_ip", clientIP(r)),
+				)
+				writeThrottled(w, r, limiter.PolicyAuth, failures, ipKey, d.RetryAfter)
+				return
+			}
+
+			ownerID, err := authenticate(r, a)
+			switch {
+			case err == nil:
+				next.ServeHTTP(w, r.WithContext(auth.WithOwner(r.Context(), ownerID)))
+			case errors.Is(err, model.ErrUnauthorized):
+				if failures != nil {
+					failures.Take(ipKey)
+				}
+				if r.Header.Get("Authorization") != "" {
+					log.WithContext(r.Context()).Warn("Invalid API key",
+						logger.Any("method", r.Method),
+						logger.Any("path", r.URL.Path),
+						logger.Any("client_ip", clientIP(r)),
+					)
+				}
+				unauthorized(w, r)
+			default:
+				log.WithContext(r.Context()).Error("Unable to authenticate request", logger.Error(err))
+				problem.Write(w, r, problem.New(problem.Internal, ""))
+			}
+		})
+	}
+}
+
+func peek(rl rateLimiter, key string) limiter.Decision {
+	if rl == nil {
+		return limiter.Decision{Allowed: true}
+	}
+	return rl.Peek(key)
+}
+
+func authenticate(r *http.Request, a authenticator) (int64, error) {
+	key, ok := bearerToken(r)
+	if !ok {
+		return 0, model.ErrUnauthorized
+	}
+	return a.Authenticate(r.Context(), key)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	key = strings.TrimSpace(key)
+	return key, ok && key != ""
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	problem.Write(w, r, problem.New(problem.Unauthorized, "A valid API key is required."))
+}
+
+func clientIP(r *http.Request) string {
+	addr, _ := clientip.FromContext(r.Context())
+	return addr.String()
+}
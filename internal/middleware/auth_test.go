@@ -0,0 +1,128 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/auth"
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/limiter"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+	"github.com/domovonok/url-shortener/internal/middleware"
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+type fakeAuthenticator struct {
+	calls int
+}
+
+func (a *fakeAuthenticator) Authenticate(_ context.Context, key string) (int64, error) {
+	a.calls++
+
+	switch key {
+	case "valid":
+		return 7, nil
+	case "broken":
+		return 0, errors.New("database is down")
+	}
+	return 0, model.ErrUnauthorized
+}
+
+// recordingLogger keeps the messages of the entries written through it.
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) record(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, msg)
+}
+
+func (l *recordingLogger) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	messages := l.messages
+	l.messages = nil
+	return messages
+}
+
+func (l *recordingLogger) Info(msg string, _ ...logger.Field)        { l.record(msg) }
+func (l *recordingLogger) Error(msg string, _ ...logger.Field)       { l.record(msg) }
+func (l *recordingLogger) Debug(msg string, _ ...logger.Field)       { l.record(msg) }
+func (l *recordingLogger) Warn(msg string, _ ...logger.Field)        { l.record(msg) }
+func (l *recordingLogger) Fatal(msg string, _ ...logger.Field)       { l.record(msg) }
+func (l *recordingLogger) Sync() error                               { return nil }
+func (l *recordingLogger) With(...logger.Field) logger.Logger        { return l }
+func (l *recordingLogger) WithContext(context.Context) logger.Logger { return l }
+
+func TestAuth(t *testing.T) {
+	t.Parallel()
+
+	m := &metrics.PrometheusMetrics{
+		RateLimitRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests"}, []string{"policy", "result"}),
+	}
+	failures := limiter.NewKeyed(config.RateLimitPolicy{
+		Tiers: map[string]config.BucketConfig{limiter.TierIP: {Capacity: 2, RefillRate: 1}},
+	}, time.Minute)
+	a := &fakeAuthenticator{}
+	log := &recordingLogger{}
+
+	var owner int64
+	handler := middleware.Auth(a, failures, log, m)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			owner, _ = auth.OwnerFromContext(r.Context())
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/links", nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("Bearer valid")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, int64(7), owner)
+	require.Empty(t, log.take())
+
+	w = serve("Bearer broken")
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, []string{"Unable to authenticate request"}, log.take())
+
+	w = serve("")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	require.Empty(t, log.take(), "a missing key is not worth a warning")
+
+	w = serve("Bearer guessed")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	require.Equal(t, []string{"Invalid API key"}, log.take())
+
+	// Both failures used up the bucket, so even a valid key is turned away
+	// without being looked up.
+	calls := a.calls
+	w = serve("Bearer valid")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	require.Equal(t, `"auth";r=0;t=2`, w.Header().Get("RateLimit"))
+	require.Equal(t, calls, a.calls)
+	require.Equal(t, []string{"Too many failed authentications"}, log.take())
+	require.Equal(t, 1.0, testutil.ToFloat64(m.RateLimitRequestsTotal.WithLabelValues(limiter.PolicyAuth, "throttled")))
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/domovonok/url-shortener/internal/clientip"
+)
+
+type ipResolver interface {
+	Resolve(r *http.Request) netip.Addr
+}
+
+// ClientIP stores the resolved client address in the request context.
+func ClientIP(resolver ipResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := clientip.WithAddr(r.Context(), resolver.Resolve(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/domovonok/url-shortener/internal/clientip"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+	"github.com/domovonok/url-shortener/internal/transport/http/problem"
+)
+
+type ipFilter interface {
+	Allowed(group string, addr netip.Addr) bool
+}
+
+// IPFilter rejects clients that the allow and deny lists of group do not
+// permit.
+func IPFilter(group string, f ipFilter, log logger.Logger, m *metrics.PrometheusMetrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			addr, _ := clientip.FromContext(r.Context())
+			if !f.Allowed(group, addr) {
+				m.IPFilterRejectedTotal.WithLabelValues(group).Inc()
+				log.WithContext(r.Context()).Warn("Request rejected by IP filter",
+					logger.Any("group", group),
+					logger.Any("method", r.Method),
+					logger.Any("path", r.URL.Path),
+					logger.Any("client_ip", addr.String()),
+				)
+
+				problem.Write(w, r, problem.New(problem.Forbidden, "Your network is not allowed to use this endpoint."))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,41 +1,128 @@
 package middleware
 
 import (
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/domovonok/url-shortener/internal/auth"
+	"github.com/domovonok/url-shortener/internal/clientip"
+	"github.com/domovonok/url-shortener/internal/limiter"
 	"github.com/domovonok/url-shortener/internal/logger"
 	"github.com/domovonok/url-shortener/internal/metrics"
+	"github.com/domovonok/url-shortener/internal/transport/http/problem"
 )
 
-type tokenBucket interface {
-	Allow() bool
-	Capacity() int
-	Remaining() int
+type rateLimiter interface {
+	Take(key string) limiter.Decision
+	Capacity(key string) int
+	Peek(key string) limiter.Decision
+	RefillRate(key string) int
 }
 
-func RateLimitMiddleware(limiter tokenBucket, log logger.Logger, m *metrics.PrometheusMetrics) func(http.Handler) http.Handler {
+// KeyFunc identifies the client a request is rate limited as. An empty key
+// leaves the request to a later limit.
+type KeyFunc func(r *http.Request) string
+
+// AnonymousKey limits requests without an API key per client IP. Requests
+// presenting a key are left to OwnerKey once Auth has checked it; until then,
+// made-up keys only cost their IP's failed authentication budget, so they
+// cannot each claim a bucket of their own.
+func AnonymousKey(r *http.Request) string {
+	if _, ok := bearerToken(r); ok {
+		return ""
+	}
+	return IPKey(r)
+}
+
+// OwnerKey limits authenticated requests per owner of the verified API key.
+func OwnerKey(r *http.Request) string {
+	if ownerID, ok := auth.OwnerFromContext(r.Context()); ok {
+		return limiter.Key(limiter.TierKey, strconv.FormatInt(ownerID, 10))
+	}
+	return ""
+}
+
+// IPKey limits every request per client IP.
+func IPKey(r *http.Request) string {
+	if addr, ok := clientip.FromContext(r.Context()); ok {
+		return limiter.Key(limiter.TierIP, addr.String())
+	}
+	return limiter.Key(limiter.TierIP, r.RemoteAddr)
+}
+
+const (
+	rateLimitAllowed   = "allowed"
+	rateLimitThrottled = "throttled"
+)
+
+// RateLimitMiddleware applies the named policy enforced by rl.
+func RateLimitMiddleware(policy string, rl rateLimiter, keyFunc KeyFunc, log logger.Logger, m *metrics.PrometheusMetrics) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if !limiter.Allow() {
+			key := keyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			d := rl.Take(key)
+			if !d.Allowed {
 				m.RateLimitExceededTotal.Inc()
-				log.Warn("Rate limit exceeded",
+				m.RateLimitRequestsTotal.WithLabelValues(policy, rateLimitThrottled).Inc()
+				log.WithContext(r.Context()).Warn("Rate limit exceeded",
+					logger.Any("policy", policy),
 					logger.Any("method", r.Method),
 					logger.Any("path", r.URL.Path),
-					logger.Any("remote_addr", r.RemoteAddr),
+					logger.Any("key", key),
 				)
 
-				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limiter.Capacity()))
-				w.Header().Set("X-RateLimit-Remaining", "0")
-				w.WriteHeader(http.StatusTooManyRequests)
-				_, _ = w.Write([]byte("Rate limit exceeded"))
+				writeThrottled(w, r, policy, rl, key, d.RetryAfter)
 				return
 			}
 
-			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limiter.Capacity()))
-			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(limiter.Remaining()))
+			m.RateLimitRequestsTotal.WithLabelValues(policy, rateLimitAllowed).Inc()
+			setRateLimitHeaders(w, policy, rl, key, d.Remaining)
 
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+func writeThrottled(w http.ResponseWriter, r *http.Request, policy string, rl rateLimiter, key string, wait time.Duration) {
+	setRateLimitHeaders(w, policy, rl, key, 0)
+	retryAfter := max(seconds(wait), 1)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	problem.Write(w, r, problem.New(problem.RateLimited,
+		fmt.Sprintf("Retry in %d seconds.", retryAfter)))
+}
+
+// setRateLimitHeaders describes the client's bucket with the IETF RateLimit
+// and RateLimit-Policy fields, keeping the legacy X-RateLimit-* pair. The
+// window is the time a bucket takes to refill completely, and the reset is
+// the time until the client's bucket is full again.
+func setRateLimitHeaders(w http.ResponseWriter, policy string, rl rateLimiter, key string, remaining int) {
+	capacity, rate := rl.Capacity(key), rl.RefillRate(key)
+
+	quota := fmt.Sprintf("%q;q=%d", policy, capacity)
+	limit := fmt.Sprintf("%q;r=%d", policy, remaining)
+	if rate > 0 {
+		quota += fmt.Sprintf(";w=%d", ceilDiv(capacity, rate))
+		limit += fmt.Sprintf(";t=%d", ceilDiv(capacity-remaining, rate))
+	}
+
+	w.Header().Set("RateLimit-Policy", quota)
+	w.Header().Set("RateLimit", limit)
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(capacity))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,132 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/limiter"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+	"github.com/domovonok/url-shortener/internal/middleware"
+	"github.com/domovonok/url-shortener/internal/transport/http/problem"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	m := &metrics.PrometheusMetrics{
+		RateLimitExceededTotal: prometheus.NewCounter(prometheus.CounterOpts{Name: "exceeded"}),
+		RateLimitRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests"}, []string{"policy", "result"}),
+	}
+	rl := limiter.NewKeyed(config.RateLimitPolicy{
+		Tiers: map[string]config.BucketConfig{limiter.TierIP: {Capacity: 2, RefillRate: 1}},
+	}, time.Minute)
+
+	handler := middleware.RateLimitMiddleware(limiter.PolicyCreate, rl, middleware.IPKey, logger.MustInit(false), m)(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }),
+	)
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+		return w
+	}
+
+	w := serve()
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, `"create";q=2;w=2`, w.Header().Get("RateLimit-Policy"))
+	require.Equal(t, `"create";r=1;t=1`, w.Header().Get("RateLimit"))
+	require.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	require.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	require.Empty(t, w.Header().Get("Retry-After"))
+
+	w = serve()
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, `"create";r=0;t=2`, w.Header().Get("RateLimit"))
+
+	w = serve()
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+	require.Equal(t, `"create";q=2;w=2`, w.Header().Get("RateLimit-Policy"))
+	require.Equal(t, `"create";r=0;t=2`, w.Header().Get("RateLimit"))
+	require.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.RateLimitExceededTotal))
+	require.Equal(t, 2.0, testutil.ToFloat64(m.RateLimitRequestsTotal.WithLabelValues(limiter.PolicyCreate, "allowed")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.RateLimitRequestsTotal.WithLabelValues(limiter.PolicyCreate, "throttled")))
+}
+
+// recordingLimiter remembers the keys buckets were taken from.
+type recordingLimiter struct {
+	*limiter.Keyed
+	mu   sync.Mutex
+	keys map[string]int
+}
+
+func (l *recordingLimiter) Take(key string) limiter.Decision {
+	l.mu.Lock()
+	l.keys[key]++
+	l.mu.Unlock()
+	return l.Keyed.Take(key)
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	t.Parallel()
+
+	m := &metrics.PrometheusMetrics{
+		RateLimitExceededTotal: prometheus.NewCounter(prometheus.CounterOpts{Name: "exceeded"}),
+		RateLimitRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests"}, []string{"policy", "result"}),
+	}
+	newLimiter := func(capacity int) *recordingLimiter {
+		return &recordingLimiter{
+			Keyed: limiter.NewKeyed(config.RateLimitPolicy{Tiers: map[string]config.BucketConfig{
+				limiter.TierIP:  {Capacity: capacity, RefillRate: 1},
+				limiter.TierKey: {Capacity: 100, RefillRate: 10},
+			}}, time.Minute),
+			keys: map[string]int{},
+		}
+	}
+	create, failures := newLimiter(5), newLimiter(3)
+	log := logger.MustInit(false)
+
+	handler := middleware.RateLimitMiddleware(limiter.PolicyCreate, create, middleware.AnonymousKey, log, m)(
+		middleware.Auth(&fakeAuthenticator{}, failures, log, m)(
+			middleware.RateLimitMiddleware(limiter.PolicyCreate, create, middleware.OwnerKey, log, m)(
+				http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }),
+			),
+		),
+	)
+	serve := func(authorization string) int {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusNoContent, serve("Bearer valid"))
+	require.Equal(t, http.StatusUnauthorized, serve(""))
+
+	// Made-up keys from one IP share its failed authentication bucket and
+	// never get buckets of their own.
+	codes := map[int]int{}
+	for i := range 50 {
+		codes[serve("Bearer bogus-"+strconv.Itoa(i))]++
+	}
+	require.Equal(t, map[int]int{http.StatusUnauthorized: 2, http.StatusTooManyRequests: 48}, codes)
+
+	require.Equal(t, map[string]int{"key:7": 1, "ip:192.0.2.1:1234": 1}, create.keys)
+	require.Equal(t, map[string]int{"ip:192.0.2.1:1234": 3}, failures.keys)
+}
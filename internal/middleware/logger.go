@@ -27,11 +27,12 @@ func Logger(log logger.Logger) func(next http.Handler) http.Handler {
 				routePattern = r.URL.Path
 			}
 
-			log.Debug("HTTP request",
+			log.WithContext(r.Context()).Debug("HTTP request",
 				logger.Any("method", r.Method),
 				logger.Any("path", routePattern),
 				logger.Any("status", rw.statusCode),
 				logger.Any("duration", duration),
+				logger.Any("client_ip", clientIP(r)),
 			)
 		})
 	}
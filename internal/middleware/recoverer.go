@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/transport/http/problem"
 )
 
 func Recoverer(log logger.Logger) func(http.Handler) http.Handler {
@@ -11,8 +12,8 @@ func Recoverer(log logger.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
-					log.Error("Panic recovered", logger.Any("error", err))
-					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					log.WithContext(r.Context()).Error("Panic recovered", logger.Any("error", err))
+					problem.Write(w, r, problem.New(problem.Internal, ""))
 				}
 			}()
 			next.ServeHTTP(w, r)
@@ -0,0 +1,61 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/middleware"
+	"github.com/domovonok/url-shortener/internal/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "valid id is echoed", incoming: "3f2a9c1e-7b1d-4c2e-9a51-0d6c2f1b8e4a", keep: true},
+		{name: "missing id is generated"},
+		{name: "invalid id is replaced", incoming: "with space"},
+		{name: "overlong id is replaced", incoming: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				fromContext string
+				fields      []logger.Field
+			)
+			handler := middleware.RequestID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				fromContext, _ = requestid.FromContext(r.Context())
+				fields = logger.FieldsFromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				r.Header.Set("X-Request-ID", tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			id := w.Header().Get("X-Request-ID")
+			if tt.keep {
+				require.Equal(t, tt.incoming, id)
+			} else {
+				require.NotEqual(t, tt.incoming, id)
+				require.True(t, requestid.Valid(id))
+			}
+			require.Equal(t, id, fromContext)
+			require.Equal(t, []logger.Field{logger.Any("request_id", id)}, fields)
+		})
+	}
+}
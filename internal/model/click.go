@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type Click struct {
+	LinkID    int64
+	Referrer  string
+	UserAgent string
+	IP        string
+	ClickedAt time.Time
+}
@@ -1,8 +1,55 @@
 package model
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
-	ErrInvalidInput = errors.New("invalid input")
-	ErrCodeNotFound = errors.New("code not found")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrInvalidURL         = errors.New("invalid url")
+	ErrDestinationDenied  = errors.New("destination not allowed")
+	ErrDestinationBlocked = errors.New("destination is blocklisted")
+	ErrCodeNotFound       = errors.New("code not found")
+	ErrInvalidAlias       = errors.New("invalid alias")
+	ErrAliasTaken         = errors.New("alias already taken")
+	ErrAliasConflict      = errors.New("link already has another alias")
+	ErrInvalidExpiry      = errors.New("invalid expiration")
+	ErrLinkExpired        = errors.New("link expired")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrLinkExists         = errors.New("link with this url already exists")
+
+	// ErrDestinationUnresolved reports that the destination host could not be
+	// looked up for reasons other than it not existing, such as a timeout.
+	ErrDestinationUnresolved = errors.New("destination host could not be resolved")
 )
+
+// FieldError explains why a single input field was rejected.
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+// ValidationError reports rejected input fields. It wraps the sentinel error
+// naming the kind of failure, so errors.Is keeps working on it.
+type ValidationError struct {
+	Err    error
+	Fields []FieldError
+}
+
+func NewValidationError(err error, field, reason string) *ValidationError {
+	return &ValidationError{Err: err, Fields: []FieldError{{Field: field, Reason: reason}}}
+}
+
+func (e *ValidationError) Error() string {
+	details := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		details = append(details, f.Field+" "+f.Reason)
+	}
+	return e.Err.Error() + ": " + strings.Join(details, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
@@ -3,7 +3,17 @@ package model
 import "time"
 
 type Link struct {
+	// ID is the database id behind the generated code. It is stable across
+	// codec changes and key rotations, unlike the code itself.
+	ID        int64
 	Url       string
 	Code      string
+	Alias     string
 	CreatedAt time.Time
+	ExpiresAt *time.Time
+	OwnerID   int64
+}
+
+func (l Link) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
 }
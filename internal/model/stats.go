@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+const (
+	StatsIntervalHour = "hour"
+	StatsIntervalDay  = "day"
+)
+
+type StatsQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+}
+
+// ClickSummary aggregates the clicks within the queried range.
+type ClickSummary struct {
+	TotalClicks    int64
+	UniqueVisitors int64
+	FirstClick     *time.Time
+	LastClick      *time.Time
+}
+
+type StatsBucket struct {
+	Start  time.Time
+	Clicks int64
+}
+
+type StatsCount struct {
+	Value  string
+	Clicks int64
+}
+
+type LinkStats struct {
+	Code string
+	ClickSummary
+	Query         StatsQuery
+	Series        []StatsBucket
+	TopReferrers  []StatsCount
+	TopUserAgents []StatsCount
+}
@@ -0,0 +1,55 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+const tableAPIKeys = "api_keys"
+
+type Repo struct {
+	pool         dbPool
+	queryBuilder sq.StatementBuilderType
+}
+
+func New(pool dbPool) *Repo {
+	return &Repo{
+		pool:         pool,
+		queryBuilder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *Repo) Create(ctx context.Context, ownerID int64, name string, hash []byte) (int64, error) {
+	query, args, _ := r.queryBuilder.
+		Insert(tableAPIKeys).
+		Columns("owner_id", "name", "key_hash").
+		Values(ownerID, name, hash).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int64
+	err := r.pool.QueryRow(ctx, query, args...).Scan(&id)
+	return id, err
+}
+
+func (r *Repo) OwnerByHash(ctx context.Context, hash []byte) (int64, error) {
+	query, args, _ := r.queryBuilder.
+		Select("owner_id").
+		From(tableAPIKeys).
+		Where(sq.Eq{"key_hash": hash, "revoked_at": nil}).
+		ToSql()
+
+	var ownerID int64
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&ownerID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, model.ErrUnauthorized
+		}
+		return 0, err
+	}
+	return ownerID, nil
+}
@@ -0,0 +1,45 @@
+package click
+
+import (
+	"context"
+	"net/netip"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+const tableClicks = "clicks"
+
+var clickColumns = []string{"link_id", "clicked_at", "referrer", "user_agent", "ip"}
+
+type Repo struct {
+	pool         dbPool
+	queryBuilder sq.StatementBuilderType
+}
+
+func New(pool dbPool) *Repo {
+	return &Repo{
+		pool:         pool,
+		queryBuilder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r *Repo) Insert(ctx context.Context, clicks []model.Click) error {
+	_, err := r.pool.CopyFrom(ctx, pgx.Identifier{tableClicks}, clickColumns,
+		pgx.CopyFromSlice(len(clicks), func(i int) ([]any, error) {
+			c := clicks[i]
+			return []any{c.LinkID, c.ClickedAt, c.Referrer, c.UserAgent, parseIP(c.IP)}, nil
+		}),
+	)
+	return err
+}
+
+func parseIP(s string) any {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return nil
+	}
+	return addr
+}
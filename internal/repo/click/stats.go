@@ -0,0 +1,88 @@
+package click
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+// Buckets without clicks are generated as well, so the series has no gaps.
+const seriesQuery = `
+SELECT b.start, COUNT(c.id)
+FROM generate_series(
+    date_trunc($1, $2::timestamptz),
+    $3::timestamptz - interval '1 microsecond',
+    ('1 ' || $1)::interval
+) AS b(start)
+LEFT JOIN clicks c
+    ON c.link_id = $4
+   AND c.clicked_at >= GREATEST(b.start, $2::timestamptz)
+   AND c.clicked_at < LEAST(b.start + ('1 ' || $1)::interval, $3::timestamptz)
+GROUP BY b.start
+ORDER BY b.start`
+
+const userAgentFamily = `CASE
+    WHEN user_agent = '' THEN 'Unknown'
+    WHEN user_agent ~* '(bot|crawl|spider|slurp)' THEN 'Bot'
+    WHEN user_agent LIKE '%Edg/%' THEN 'Edge'
+    WHEN user_agent LIKE '%OPR/%' THEN 'Opera'
+    WHEN user_agent LIKE '%Firefox/%' THEN 'Firefox'
+    WHEN user_agent LIKE '%Chrome/%' THEN 'Chrome'
+    WHEN user_agent LIKE '%Safari/%' THEN 'Safari'
+    WHEN user_agent LIKE 'curl/%' THEN 'curl'
+    ELSE 'Other'
+END`
+
+// Summary covers the same range as the series and top lists.
+func (r *Repo) Summary(ctx context.Context, linkID int64, q model.StatsQuery) (model.ClickSummary, error) {
+	query, args, _ := r.queryBuilder.
+		Select("COUNT(*)", "COUNT(DISTINCT ip)", "MIN(clicked_at)", "MAX(clicked_at)").
+		From(tableClicks).
+		Where(sq.Eq{"link_id": linkID}).
+		Where(sq.GtOrEq{"clicked_at": q.From}).
+		Where(sq.Lt{"clicked_at": q.To}).
+		ToSql()
+
+	var res model.ClickSummary
+	err := r.pool.QueryRow(ctx, query, args...).
+		Scan(&res.TotalClicks, &res.UniqueVisitors, &res.FirstClick, &res.LastClick)
+	return res, err
+}
+
+func (r *Repo) Series(ctx context.Context, linkID int64, q model.StatsQuery) ([]model.StatsBucket, error) {
+	rows, err := r.pool.Query(ctx, seriesQuery, q.Interval, q.From, q.To, linkID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[model.StatsBucket])
+}
+
+func (r *Repo) TopReferrers(ctx context.Context, linkID int64, q model.StatsQuery, limit uint64) ([]model.StatsCount, error) {
+	return r.top(ctx, "referrer", linkID, q, limit)
+}
+
+func (r *Repo) TopUserAgents(ctx context.Context, linkID int64, q model.StatsQuery, limit uint64) ([]model.StatsCount, error) {
+	return r.top(ctx, userAgentFamily, linkID, q, limit)
+}
+
+func (r *Repo) top(ctx context.Context, expr string, linkID int64, q model.StatsQuery, limit uint64) ([]model.StatsCount, error) {
+	query, args, _ := r.queryBuilder.
+		Select(expr+" AS value", "COUNT(*) AS clicks").
+		From(tableClicks).
+		Where(sq.Eq{"link_id": linkID}).
+		Where(sq.GtOrEq{"clicked_at": q.From}).
+		Where(sq.Lt{"clicked_at": q.To}).
+		GroupBy("value").
+		OrderBy("clicks DESC", "value").
+		Limit(limit).
+		ToSql()
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[model.StatsCount])
+}
@@ -1,54 +1,266 @@
 package link
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/domovonok/url-shortener/internal/config"
 	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
 	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/tracing"
+)
+
+const (
+	cacheResultHit         = "hit"
+	cacheResultNegativeHit = "negative_hit"
+	cacheResultMiss        = "miss"
+	cacheResultCoalesced   = "coalesced"
 )
 
+var tracer = otel.Tracer("github.com/domovonok/url-shortener/internal/repo/link")
+
+// notFoundEntry marks a code that is known not to exist.
+var notFoundEntry = []byte("-")
+
 type CachedRepo struct {
-	r   baseRepo
-	c   cache
-	log logger.Logger
+	r           baseRepo
+	c           cache
+	local       localCache
+	publisher   publisher
+	ttl         time.Duration
+	negativeTtl time.Duration
+	coalesce    bool
+	group       singleflight.Group
+	m           *metrics.PrometheusMetrics
+	log         logger.Logger
+}
+
+func NewCached(r baseRepo, c cache, cfg config.CacheConfig, m *metrics.PrometheusMetrics, l logger.Logger) *CachedRepo {
+	return &CachedRepo{
+		r:           r,
+		c:           c,
+		ttl:         cfg.Ttl,
+		negativeTtl: cfg.NegativeTtl,
+		coalesce:    cfg.Coalesce,
+		m:           m,
+		log:         l,
+	}
 }
 
-func NewCached(r baseRepo, c cache, l logger.Logger) *CachedRepo {
-	return &CachedRepo{r: r, c: c, log: l}
+// UseLocal serves reads from local before the shared cache. Evicted keys are
+// announced to other instances through p. It must be called before the
+// repository is used.
+func (cr *CachedRepo) UseLocal(local localCache, p publisher) {
+	cr.local = local
+	cr.publisher = p
 }
 
-func (cr *CachedRepo) Create(ctx context.Context, url string) (model.Link, error) {
-	res, err := cr.r.Create(ctx, url)
+// CachedSize approximates the memory a link takes in the local cache.
+func CachedSize(l model.Link) int {
+	const overhead = 64
+	return overhead + len(l.Url) + len(l.Code) + len(l.Alias)
+}
+
+func (cr *CachedRepo) Create(ctx context.Context, l model.Link) (model.Link, bool, error) {
+	res, created, err := cr.r.Create(ctx, l)
 	if err == nil {
-		if data, err := json.Marshal(res); err == nil {
-			_ = cr.c.Set(ctx, key(res.Code), data)
+		// The link may have been revived while other instances still hold a
+		// negative entry for it.
+		cr.invalidate(ctx, res)
+		cr.set(ctx, res, res.Code)
+		if res.Alias != "" {
+			cr.set(ctx, res, res.Alias)
+		}
+	}
+	return res, created, err
+}
+
+func (cr *CachedRepo) Get(ctx context.Context, code string) (_ model.Link, err error) {
+	ctx, span := tracer.Start(ctx, "link.CachedRepo.Get", trace.WithAttributes(attribute.String("link.code", code)))
+	defer func() { tracing.End(span, err, model.ErrCodeNotFound) }()
+
+	if l, ok := cr.cached(ctx, code); ok {
+		if l.ID == 0 {
+			span.SetAttributes(attribute.String("cache.result", cacheResultNegativeHit))
+			cr.m.CacheRequestsTotal.WithLabelValues(cacheResultNegativeHit).Inc()
+			cr.log.WithContext(ctx).Debug("Negative cache hit", logger.Any("code", code))
+			return model.Link{}, model.ErrCodeNotFound
 		}
+		span.SetAttributes(attribute.String("cache.result", cacheResultHit))
+		cr.m.CacheRequestsTotal.WithLabelValues(cacheResultHit).Inc()
+		cr.log.WithContext(ctx).Debug("Cache hit", logger.Any("code", code))
+		return l, nil
+	}
+
+	if !cr.coalesce {
+		span.SetAttributes(attribute.String("cache.result", cacheResultMiss))
+		cr.m.CacheRequestsTotal.WithLabelValues(cacheResultMiss).Inc()
+		return cr.load(ctx, code)
+	}
+
+	// Concurrent misses for the same code share one database query. The query
+	// must not be cancelled together with whichever request happened to start
+	// it, but every caller stops waiting for it once its own context is done.
+	// Only the caller whose function runs counts as a miss.
+	leader := false
+	ch := cr.group.DoChan(code, func() (any, error) {
+		leader = true
+		span.SetAttributes(attribute.String("cache.result", cacheResultMiss))
+		cr.m.CacheRequestsTotal.WithLabelValues(cacheResultMiss).Inc()
+		return cr.load(context.WithoutCancel(ctx), code)
+	})
+	select {
+	case <-ctx.Done():
+		return model.Link{}, ctx.Err()
+	case res := <-ch:
+		if !leader {
+			span.SetAttributes(attribute.String("cache.result", cacheResultCoalesced))
+			cr.m.CacheRequestsTotal.WithLabelValues(cacheResultCoalesced).Inc()
+		}
+		if res.Err != nil {
+			return model.Link{}, res.Err
+		}
+		return res.Val.(model.Link), nil
 	}
-	return res, err
 }
 
-func (cr *CachedRepo) Get(ctx context.Context, code string) (model.Link, error) {
-	if data, err := cr.c.Get(ctx, key(code)); err == nil {
-		var l model.Link
-		if json.Unmarshal(data, &l) == nil {
-			cr.log.Debug("Cache hit", logger.Any("code", code))
-			return l, nil
+// cached looks code up in the local cache, then in the shared one, and keeps
+// what the shared cache returned locally. A zero link marks a code that is
+// known not to exist.
+func (cr *CachedRepo) cached(ctx context.Context, code string) (model.Link, bool) {
+	if cr.local != nil {
+		if l, err := cr.local.Get(ctx, key(code)); err == nil && !l.Expired(time.Now()) {
+			return detach(l), true
 		}
-	} else {
-		cr.log.Debug("Cache miss", logger.Any("code", code), logger.Error(err))
 	}
 
+	data, err := cr.c.Get(ctx, key(code))
+	if err != nil {
+		cr.log.WithContext(ctx).Debug("Cache miss", logger.Any("code", code), logger.Error(err))
+		return model.Link{}, false
+	}
+	if bytes.Equal(data, notFoundEntry) {
+		cr.setLocal(ctx, code, model.Link{}, cr.negativeTtl)
+		return model.Link{}, true
+	}
+	// Entries cached before links carried their id are treated as misses.
+	var l model.Link
+	if json.Unmarshal(data, &l) != nil || l.ID == 0 || l.Expired(time.Now()) {
+		return model.Link{}, false
+	}
+	cr.setLocal(ctx, code, l, cr.linkTtl(l))
+	return l, true
+}
+
+func (cr *CachedRepo) load(ctx context.Context, code string) (_ model.Link, err error) {
+	ctx, span := tracer.Start(ctx, "link.CachedRepo.load")
+	defer func() { tracing.End(span, err, model.ErrCodeNotFound) }()
+
 	res, err := cr.r.Get(ctx, code)
 	if err != nil {
+		if errors.Is(err, model.ErrCodeNotFound) && cr.negativeTtl > 0 {
+			cr.setLocal(ctx, code, model.Link{}, cr.negativeTtl)
+			_ = cr.c.Set(ctx, key(code), notFoundEntry, cr.negativeTtl)
+		}
 		return model.Link{}, err
 	}
 
-	if data, err := json.Marshal(res); err == nil {
-		_ = cr.c.Set(ctx, key(code), data)
+	cr.set(ctx, res, code)
+
+	return res, nil
+}
+
+func (cr *CachedRepo) Update(ctx context.Context, l model.Link) (model.Link, error) {
+	res, err := cr.r.Update(ctx, l)
+	if err != nil {
+		return model.Link{}, err
 	}
+	cr.invalidate(ctx, l)
+	return res, nil
+}
+
+func (cr *CachedRepo) Delete(ctx context.Context, l model.Link) error {
+	if err := cr.r.Delete(ctx, l); err != nil {
+		return err
+	}
+	cr.invalidate(ctx, l)
+	return nil
+}
 
-	return cr.r.Get(ctx, code)
+// invalidate drops every cache entry the link is known under. Codes issued
+// with a rotated codec key are cached under their own spelling and expire
+// with the cache TTL.
+func (cr *CachedRepo) invalidate(ctx context.Context, l model.Link) {
+	for _, code := range []string{l.Code, l.Alias} {
+		if code == "" {
+			continue
+		}
+		if err := cr.evict(ctx, key(code)); err != nil {
+			cr.log.WithContext(ctx).Warn("Unable to invalidate cached link", logger.Any("code", code), logger.Error(err))
+		}
+	}
+}
+
+// evict drops key from both caches and from the local caches of other
+// instances. Other instances are told even when the shared cache cannot be
+// reached, since their local copies would otherwise outlive the change.
+func (cr *CachedRepo) evict(ctx context.Context, key string) error {
+	if cr.local != nil {
+		_ = cr.local.Delete(ctx, key)
+	}
+	err := cr.c.Delete(ctx, key)
+	if cr.publisher != nil {
+		err = errors.Join(err, cr.publisher.Publish(ctx, key))
+	}
+	return err
+}
+
+// set caches the link under code for the configured TTL, but never past the
+// link's own expiration.
+func (cr *CachedRepo) set(ctx context.Context, l model.Link, code string) {
+	ttl := cr.linkTtl(l)
+	if ttl <= 0 {
+		return
+	}
+
+	cr.setLocal(ctx, code, l, ttl)
+	if data, err := json.Marshal(l); err == nil {
+		_ = cr.c.Set(ctx, key(code), data, ttl)
+	}
+}
+
+// setLocal keeps a private copy of l in the local cache, if there is one.
+func (cr *CachedRepo) setLocal(ctx context.Context, code string, l model.Link, ttl time.Duration) {
+	if cr.local != nil && ttl > 0 {
+		_ = cr.local.Set(ctx, key(code), detach(l), ttl)
+	}
+}
+
+func (cr *CachedRepo) linkTtl(l model.Link) time.Duration {
+	ttl := cr.ttl
+	if l.ExpiresAt != nil {
+		ttl = min(ttl, time.Until(*l.ExpiresAt))
+	}
+	return ttl
+}
+
+// detach copies the parts of l that are shared by reference, so that links
+// held by the local cache cannot be modified by their readers.
+func detach(l model.Link) model.Link {
+	if l.ExpiresAt != nil {
+		expiresAt := *l.ExpiresAt
+		l.ExpiresAt = &expiresAt
+	}
+	return l
 }
 
 func key(code string) string {
@@ -0,0 +1,286 @@
+package link_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/domovonok/url-shortener/internal/cache"
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/repo/link"
+)
+
+func newMetrics() *metrics.PrometheusMetrics {
+	return &metrics.PrometheusMetrics{
+		CacheRequestsTotal:       prometheus.NewCounterVec(prometheus.CounterOpts{Name: "cache"}, []string{"result"}),
+		LocalCacheRequestsTotal:  prometheus.NewCounterVec(prometheus.CounterOpts{Name: "local"}, []string{"result"}),
+		LocalCacheEvictionsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "evictions"}, []string{"reason"}),
+		LocalCacheEntries:        prometheus.NewGauge(prometheus.GaugeOpts{Name: "entries"}),
+		LocalCacheBytes:          prometheus.NewGauge(prometheus.GaugeOpts{Name: "bytes"}),
+	}
+}
+
+var cacheCfg = config.CacheConfig{
+	Ttl:         time.Minute,
+	NegativeTtl: time.Second,
+	Coalesce:    true,
+}
+
+func TestCachedRepoGet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("negative entry is cached", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		base := NewMockbaseRepo(ctrl)
+		c := NewMockcache(ctrl)
+		m := newMetrics()
+		repo := link.NewCached(base, c, cacheCfg, m, logger.MustInit(false))
+
+		c.EXPECT().Get(gomock.Any(), "link:missing").Return(nil, redis.Nil)
+		base.EXPECT().Get(gomock.Any(), "missing").Return(model.Link{}, model.ErrCodeNotFound)
+		c.EXPECT().Set(gomock.Any(), "link:missing", []byte("-"), cacheCfg.NegativeTtl).Return(nil)
+
+		_, err := repo.Get(context.Background(), "missing")
+		require.ErrorIs(t, err, model.ErrCodeNotFound)
+
+		c.EXPECT().Get(gomock.Any(), "link:missing").Return([]byte("-"), nil)
+
+		_, err = repo.Get(context.Background(), "missing")
+		require.ErrorIs(t, err, model.ErrCodeNotFound)
+		require.Equal(t, float64(1), testutil.ToFloat64(m.CacheRequestsTotal.WithLabelValues("negative_hit")))
+	})
+
+	t.Run("concurrent misses are coalesced", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		base := NewMockbaseRepo(ctrl)
+		c := NewMockcache(ctrl)
+		m := newMetrics()
+		repo := link.NewCached(base, c, cacheCfg, m, logger.MustInit(false))
+
+		want := model.Link{ID: 1, Url: "https://test.com", Code: "Code123"}
+		const callers = 10
+		var waiting sync.WaitGroup
+		waiting.Add(callers)
+		release := make(chan struct{})
+
+		// Every caller has missed the cache before the query returns. A caller
+		// descheduled right after its miss may still start a query of its own,
+		// so the test only relies on each query being counted as one miss.
+		c.EXPECT().Get(gomock.Any(), "link:Code123").
+			DoAndReturn(func(context.Context, string) ([]byte, error) {
+				waiting.Done()
+				return nil, redis.Nil
+			}).
+			Times(callers)
+		var queries atomic.Int64
+		base.EXPECT().
+			Get(gomock.Any(), "Code123").
+			DoAndReturn(func(context.Context, string) (model.Link, error) {
+				queries.Add(1)
+				<-release
+				return want, nil
+			}).
+			MinTimes(1)
+		c.EXPECT().Set(gomock.Any(), "link:Code123", gomock.Any(), cacheCfg.Ttl).Return(nil).MinTimes(1)
+
+		type result struct {
+			link model.Link
+			err  error
+		}
+		results := make(chan result, callers)
+		for range callers {
+			go func() {
+				got, err := repo.Get(context.Background(), "Code123")
+				results <- result{got, err}
+			}()
+		}
+
+		waiting.Wait()
+		close(release)
+		for range callers {
+			res := <-results
+			require.NoError(t, res.err)
+			require.Equal(t, want, res.link)
+		}
+
+		misses := testutil.ToFloat64(m.CacheRequestsTotal.WithLabelValues("miss"))
+		coalesced := testutil.ToFloat64(m.CacheRequestsTotal.WithLabelValues("coalesced"))
+		require.Equal(t, float64(queries.Load()), misses)
+		require.Equal(t, float64(callers), misses+coalesced)
+		require.Positive(t, coalesced)
+	})
+
+	t.Run("caller stops waiting when its context is done", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		base := NewMockbaseRepo(ctrl)
+		c := NewMockcache(ctrl)
+		repo := link.NewCached(base, c, cacheCfg, newMetrics(), logger.MustInit(false))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		release := make(chan struct{})
+		done := make(chan struct{})
+		var queryErr error
+
+		c.EXPECT().Get(gomock.Any(), "link:Code123").Return(nil, redis.Nil)
+		base.EXPECT().
+			Get(gomock.Any(), "Code123").
+			DoAndReturn(func(ctx context.Context, _ string) (model.Link, error) {
+				cancel()
+				<-release
+				queryErr = ctx.Err()
+				return model.Link{}, model.ErrCodeNotFound
+			})
+		c.EXPECT().Set(gomock.Any(), "link:Code123", []byte("-"), cacheCfg.NegativeTtl).
+			DoAndReturn(func(context.Context, string, []byte, time.Duration) error {
+				close(done)
+				return nil
+			})
+
+		_, err := repo.Get(ctx, "Code123")
+		require.ErrorIs(t, err, context.Canceled)
+
+		close(release)
+		<-done
+		// The shared query outlives the caller that started it.
+		require.NoError(t, queryErr)
+	})
+
+	t.Run("local cache keeps decoded links", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		base := NewMockbaseRepo(ctrl)
+		c := NewMockcache(ctrl)
+		m := newMetrics()
+		repo := link.NewCached(base, c, cacheCfg, m, logger.MustInit(false))
+		repo.UseLocal(cache.NewMemory(config.LocalCacheConfig{Ttl: time.Minute}, link.CachedSize, m), nil)
+
+		expiresAt := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+		want := model.Link{ID: 1, Url: "https://test.com", Code: "Code123", ExpiresAt: &expiresAt}
+		data, err := json.Marshal(want)
+		require.NoError(t, err)
+
+		// Only the first read reaches the shared cache.
+		c.EXPECT().Get(gomock.Any(), "link:Code123").Return(data, nil).Times(1)
+
+		// Readers cannot modify what the local cache holds.
+		for range 3 {
+			got, err := repo.Get(context.Background(), "Code123")
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+			*got.ExpiresAt = time.Time{}
+		}
+		require.Equal(t, float64(2), testutil.ToFloat64(m.LocalCacheRequestsTotal.WithLabelValues("hit")))
+	})
+}
+
+func TestCachedRepoInvalidation(t *testing.T) {
+	t.Parallel()
+
+	l := model.Link{ID: 1, Url: "https://test.com/new", Code: "Code123", Alias: "spring-sale", OwnerID: 7}
+	repoErr := errors.New("repo failure")
+
+	tests := []struct {
+		name   string
+		expect func(base *MockbaseRepo, err error)
+		call   func(repo *link.CachedRepo) error
+	}{
+		{
+			name: "update",
+			expect: func(base *MockbaseRepo, err error) {
+				base.EXPECT().Update(gomock.Any(), l).Return(l, err)
+			},
+			call: func(repo *link.CachedRepo) error {
+				_, err := repo.Update(context.Background(), l)
+				return err
+			},
+		},
+		{
+			name: "delete",
+			expect: func(base *MockbaseRepo, err error) {
+				base.EXPECT().Delete(gomock.Any(), l).Return(err)
+			},
+			call: func(repo *link.CachedRepo) error {
+				return repo.Delete(context.Background(), l)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			base := NewMockbaseRepo(ctrl)
+			c := NewMockcache(ctrl)
+			local := NewMocklocalCache(ctrl)
+			p := NewMockpublisher(ctrl)
+			repo := link.NewCached(base, c, cacheCfg, newMetrics(), logger.MustInit(false))
+			repo.UseLocal(local, p)
+
+			// Both the code and the alias are dropped everywhere.
+			tt.expect(base, nil)
+			for _, key := range []string{"link:Code123", "link:spring-sale"} {
+				local.EXPECT().Delete(gomock.Any(), key).Return(nil)
+				c.EXPECT().Delete(gomock.Any(), key).Return(nil)
+				p.EXPECT().Publish(gomock.Any(), key).Return(nil)
+			}
+			require.NoError(t, tt.call(repo))
+
+			// A failed write leaves the caches alone.
+			tt.expect(base, repoErr)
+			require.ErrorIs(t, tt.call(repo), repoErr)
+		})
+	}
+}
+
+func TestCachedRepoInvalidationPublishesWhenCacheFails(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	base := NewMockbaseRepo(ctrl)
+	c := NewMockcache(ctrl)
+	local := NewMocklocalCache(ctrl)
+	p := NewMockpublisher(ctrl)
+	repo := link.NewCached(base, c, cacheCfg, newMetrics(), logger.MustInit(false))
+	repo.UseLocal(local, p)
+
+	l := model.Link{ID: 1, Code: "Code123"}
+	base.EXPECT().Delete(gomock.Any(), l).Return(nil)
+	local.EXPECT().Delete(gomock.Any(), "link:Code123").Return(nil)
+	c.EXPECT().Delete(gomock.Any(), "link:Code123").Return(cache.ErrCircuitOpen)
+	p.EXPECT().Publish(gomock.Any(), "link:Code123").Return(nil)
+
+	require.NoError(t, repo.Delete(context.Background(), l))
+}
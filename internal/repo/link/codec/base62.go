@@ -0,0 +1,61 @@
+package codec
+
+import "math"
+
+const (
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	base62MaxLen   = 11
+)
+
+var base62Index = func() [256]int8 {
+	var idx [256]int8
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(base62Alphabet); i++ {
+		idx[base62Alphabet[i]] = int8(i)
+	}
+	return idx
+}()
+
+// Base62 produces variable-length codes: small ids get short codes.
+type Base62 struct{}
+
+func (Base62) Encode(id int64) string {
+	n := uint64(id)
+	if n == 0 {
+		return base62Alphabet[:1]
+	}
+
+	var buf [base62MaxLen]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = base62Alphabet[n%62]
+		n /= 62
+	}
+	return string(buf[i:])
+}
+
+func (Base62) Decode(code string) (int64, error) {
+	if code == "" || len(code) > base62MaxLen {
+		return 0, ErrInvalidCode
+	}
+	// Leading zeros would give several codes for the same id.
+	if len(code) > 1 && code[0] == base62Alphabet[0] {
+		return 0, ErrInvalidCode
+	}
+
+	var n uint64
+	for i := 0; i < len(code); i++ {
+		d := base62Index[code[i]]
+		if d < 0 {
+			return 0, ErrInvalidCode
+		}
+		if n > (math.MaxUint64-uint64(d))/62 {
+			return 0, ErrInvalidCode
+		}
+		n = n*62 + uint64(d)
+	}
+	return int64(n), nil
+}
@@ -0,0 +1,27 @@
+package codec
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+)
+
+// Base64 encodes all 8 bytes of the id, so every code is 11 characters long.
+// Kept for deployments that have already handed out such codes.
+type Base64 struct{}
+
+func (Base64) Encode(id int64) string {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(id))
+	return base64.RawURLEncoding.EncodeToString(buf[:])
+}
+
+func (Base64) Decode(code string) (int64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return 0, ErrInvalidCode
+	}
+	if len(decoded) != 8 {
+		return 0, ErrInvalidCode
+	}
+	return int64(binary.BigEndian.Uint64(decoded)), nil
+}
@@ -1,24 +1,42 @@
 package codec
 
 import (
-	"encoding/base64"
-	"encoding/binary"
 	"errors"
+	"fmt"
+
+	"github.com/domovonok/url-shortener/internal/config"
+)
+
+const (
+	TypeBase64  = "base64"
+	TypeBase62  = "base62"
+	TypeFeistel = "feistel"
 )
 
-func EncodeIDToCode(id int64) string {
-	var buf [8]byte
-	binary.BigEndian.PutUint64(buf[:], uint64(id))
-	return base64.RawURLEncoding.EncodeToString(buf[:])
+var ErrInvalidCode = errors.New("invalid code")
+
+type Codec interface {
+	Encode(id int64) string
+	Decode(code string) (int64, error)
 }
 
-func DecodeCodeToID(code string) (int64, error) {
-	decoded, err := base64.RawURLEncoding.DecodeString(code)
-	if err != nil {
-		return 0, err
-	}
-	if len(decoded) != 8 {
-		return 0, errors.New("invalid code payload length")
+func New(cfg config.CodecConfig) (Codec, error) {
+	switch cfg.Type {
+	case TypeBase64:
+		return Base64{}, nil
+	case TypeBase62:
+		return Base62{}, nil
+	case TypeFeistel:
+		keys, err := ParseKeys(cfg.Keys)
+		if err != nil {
+			return nil, err
+		}
+		f, err := NewFeistel(Base62{}, keys, cfg.KeyVersion)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("unknown codec type %q", cfg.Type)
 	}
-	return int64(binary.BigEndian.Uint64(decoded)), nil
 }
@@ -0,0 +1,137 @@
+package codec_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/repo/link/codec"
+)
+
+func TestBase62(t *testing.T) {
+	t.Parallel()
+
+	c := codec.Base62{}
+
+	t.Run("short codes for small ids", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, "1", c.Encode(1))
+		require.Equal(t, "z", c.Encode(61))
+		require.Equal(t, "10", c.Encode(62))
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		for _, id := range []int64{0, 1, 61, 62, 3843, 1 << 40, math.MaxInt64, -1, math.MinInt64} {
+			got, err := c.Decode(c.Encode(id))
+			require.NoError(t, err)
+			require.Equal(t, id, got)
+		}
+	})
+
+	t.Run("invalid codes", func(t *testing.T) {
+		t.Parallel()
+
+		for _, code := range []string{"", "01", "ab-c", "zzzzzzzzzzzz", "LygHa16AHYG"} {
+			_, err := c.Decode(code)
+			require.ErrorIs(t, err, codec.ErrInvalidCode, code)
+		}
+	})
+}
+
+func TestBase64(t *testing.T) {
+	t.Parallel()
+
+	c := codec.Base64{}
+
+	require.Equal(t, "AAAAAAAAAAE", c.Encode(1))
+
+	got, err := c.Decode("AAAAAAAAAAE")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), got)
+
+	_, err = c.Decode("AAAA")
+	require.ErrorIs(t, err, codec.ErrInvalidCode)
+}
+
+func TestFeistel(t *testing.T) {
+	t.Parallel()
+
+	keys := map[int][]byte{
+		1: []byte("0123456789abcdef"),
+		2: []byte("fedcba9876543210"),
+	}
+
+	oldKey, err := codec.NewFeistel(codec.Base62{}, map[int][]byte{1: keys[1]}, 1)
+	require.NoError(t, err)
+	c, err := codec.NewFeistel(codec.Base62{}, keys, 2)
+	require.NoError(t, err)
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		for _, id := range []int64{0, 1, 2, 1 << 40, math.MaxInt64, math.MinInt64} {
+			code := c.Encode(id)
+			require.Equal(t, byte('2'), code[0])
+
+			got, err := c.Decode(code)
+			require.NoError(t, err)
+			require.Equal(t, id, got)
+		}
+	})
+
+	t.Run("consecutive ids are not enumerable", func(t *testing.T) {
+		t.Parallel()
+
+		a, b := c.Encode(1), c.Encode(2)
+		require.NotEqual(t, a[:len(a)-1], b[:len(b)-1])
+	})
+
+	t.Run("rotated key still decodes", func(t *testing.T) {
+		t.Parallel()
+
+		code := oldKey.Encode(42)
+		require.Equal(t, byte('1'), code[0])
+
+		got, err := c.Decode(code)
+		require.NoError(t, err)
+		require.Equal(t, int64(42), got)
+	})
+
+	t.Run("unknown key version", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := c.Decode("9" + codec.Base62{}.Encode(42))
+		require.ErrorIs(t, err, codec.ErrInvalidCode)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := codec.NewFeistel(codec.Base62{}, keys, 3)
+		require.Error(t, err)
+
+		_, err = codec.NewFeistel(codec.Base62{}, map[int][]byte{1: []byte("short")}, 1)
+		require.Error(t, err)
+	})
+}
+
+func TestParseKeys(t *testing.T) {
+	t.Parallel()
+
+	keys, err := codec.ParseKeys("1:first-secret, 2:second:secret")
+	require.NoError(t, err)
+	require.Equal(t, map[int][]byte{
+		1: []byte("first-secret"),
+		2: []byte("second:secret"),
+	}, keys)
+
+	_, err = codec.ParseKeys("1:a,1:b")
+	require.Error(t, err)
+
+	_, err = codec.ParseKeys("nokey")
+	require.Error(t, err)
+}
@@ -1,7 +1,9 @@
+//go:generate mockgen -source ${GOFILE} -package ${GOPACKAGE}_test -destination mocks_test.go
 package link
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
@@ -13,11 +15,26 @@ type dbPool interface {
 }
 
 type cache interface {
-	Set(ctx context.Context, key string, value []byte) error
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
 	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// localCache holds decoded links in process; a zero link marks a code that is
+// known not to exist.
+type localCache interface {
+	Set(ctx context.Context, key string, value model.Link, ttl time.Duration) error
+	Get(ctx context.Context, key string) (model.Link, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type publisher interface {
+	Publish(ctx context.Context, key string) error
 }
 
 type baseRepo interface {
 	Get(ctx context.Context, code string) (model.Link, error)
-	Create(ctx context.Context, url string) (model.Link, error)
+	Create(ctx context.Context, l model.Link) (model.Link, bool, error)
+	Update(ctx context.Context, l model.Link) (model.Link, error)
+	Delete(ctx context.Context, l model.Link) error
 }
@@ -7,68 +7,177 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"github.com/domovonok/url-shortener/internal/model"
 	"github.com/domovonok/url-shortener/internal/repo/link/codec"
 )
 
-const tableLinks = "links"
+const (
+	tableLinks = "links"
+
+	pgUniqueViolation = "23505"
+	aliasConstraint   = "links_alias_key"
+	urlConstraint     = "links_url_owner_id_key"
+
+	returningColumns = "id, url, COALESCE(alias, ''), created_at, expires_at, COALESCE(owner_id, 0)"
+)
+
+var linkColumns = []string{
+	"id", "url", "COALESCE(alias, '')", "created_at", "expires_at", "COALESCE(owner_id, 0)",
+}
 
 type Repo struct {
 	pool         dbPool
+	codec        codec.Codec
 	queryBuilder sq.StatementBuilderType
 }
 
-func New(pool dbPool) *Repo {
+func New(pool dbPool, c codec.Codec) *Repo {
 	return &Repo{
 		pool:         pool,
+		codec:        c,
 		queryBuilder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
 	}
 }
 
-func (r *Repo) Create(ctx context.Context, url string) (model.Link, error) {
+// Create stores a link, or returns the owner's existing link for the same URL.
+// The flag reports whether a new link was inserted.
+func (r *Repo) Create(ctx context.Context, l model.Link) (model.Link, bool, error) {
+	var alias any
+	if l.Alias != "" {
+		// Get resolves aliases and generated codes from the same namespace.
+		// An alias the codec can decode would one day be the code of another
+		// link, whether that id exists yet or not, so such aliases are
+		// refused outright.
+		if _, err := r.codec.Decode(l.Alias); err == nil {
+			return model.Link{}, false, model.NewValidationError(model.ErrInvalidAlias, "alias", "must not look like a generated code")
+		}
+		alias = l.Alias
+	}
+
+	var owner any
+	if l.OwnerID != 0 {
+		owner = l.OwnerID
+	}
+
+	// An existing but expired or deleted link is revived with the requested
+	// expiration instead of handing back a dead code. The CTE sees the row as
+	// it was before the statement, which tells a revival from a live link.
 	query, args, _ := r.queryBuilder.
 		Insert(tableLinks).
-		Columns("url").
-		Values(url).
-		Suffix("ON CONFLICT (url) DO UPDATE SET url = EXCLUDED.url RETURNING id, created_at").
+		Prefix("WITH old AS (SELECT COALESCE(expires_at <= NOW(), false) OR deleted_at IS NOT NULL AS dead "+
+			"FROM links WHERE url = ? AND owner_id IS NOT DISTINCT FROM ?)", l.Url, owner).
+		Columns("url", "alias", "expires_at", "owner_id").
+		Values(l.Url, alias, l.ExpiresAt, owner).
+		Suffix("ON CONFLICT (url, owner_id) DO UPDATE SET " +
+			"alias = COALESCE(links.alias, EXCLUDED.alias), " +
+			"expires_at = CASE WHEN links.expires_at <= NOW() OR links.deleted_at IS NOT NULL " +
+			"THEN EXCLUDED.expires_at ELSE links.expires_at END, " +
+			"deleted_at = NULL " +
+			"RETURNING id, COALESCE(alias, ''), created_at, expires_at, (xmax = 0), " +
+			"COALESCE((SELECT dead FROM old), false)").
 		ToSql()
 
 	var (
 		id        int64
+		gotAlias  string
 		createdAt time.Time
+		expiresAt *time.Time
+		inserted  bool
+		revived   bool
 	)
 
-	if err := r.pool.QueryRow(ctx, query, args...).Scan(&id, &createdAt); err != nil {
-		return model.Link{}, handleDBError(err)
+	// xmax is only set on rows written by the ON CONFLICT update.
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&id, &gotAlias, &createdAt, &expiresAt, &inserted, &revived); err != nil {
+		return model.Link{}, false, handleDBError(err)
+	}
+
+	if l.Alias != "" && gotAlias != l.Alias {
+		return model.Link{}, false, model.ErrAliasConflict
 	}
 
 	res := model.Link{
-		Url:       url,
-		Code:      codec.EncodeIDToCode(id),
+		ID:        id,
+		Url:       l.Url,
+		Code:      r.codec.Encode(id),
+		Alias:     gotAlias,
 		CreatedAt: createdAt,
+		ExpiresAt: expiresAt,
+		OwnerID:   l.OwnerID,
 	}
 
-	return res, nil
+	return res, inserted || revived, nil
 }
 
 func (r *Repo) Get(ctx context.Context, code string) (model.Link, error) {
-	id, err := codec.DecodeCodeToID(code)
+	where := sq.Or{sq.Eq{"alias": code}}
+	if id, err := r.codec.Decode(code); err == nil {
+		where = append(where, sq.Eq{"id": id})
+	}
+
+	query, args, _ := r.queryBuilder.
+		Select(linkColumns...).
+		From(tableLinks).
+		Where(where).
+		Where(sq.Eq{"deleted_at": nil}).
+		OrderByClause("alias = ? DESC NULLS LAST", code).
+		Limit(1).
+		ToSql()
+
+	return r.scanLink(r.pool.QueryRow(ctx, query, args...))
+}
+
+// Update changes the destination of a link previously resolved by Get; it is
+// looked up by its generated code only.
+func (r *Repo) Update(ctx context.Context, l model.Link) (model.Link, error) {
+	id, err := r.codec.Decode(l.Code)
 	if err != nil {
 		return model.Link{}, model.ErrCodeNotFound
 	}
 
 	query, args, _ := r.queryBuilder.
-		Select("url", "created_at").
-		From(tableLinks).
-		Where(sq.Eq{"id": id}).
+		Update(tableLinks).
+		Set("url", l.Url).
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		Suffix("RETURNING " + returningColumns).
+		ToSql()
+
+	return r.scanLink(r.pool.QueryRow(ctx, query, args...))
+}
+
+// Delete soft-deletes a link previously resolved by Get and releases its
+// alias for reuse.
+func (r *Repo) Delete(ctx context.Context, l model.Link) error {
+	id, err := r.codec.Decode(l.Code)
+	if err != nil {
+		return model.ErrCodeNotFound
+	}
+
+	query, args, _ := r.queryBuilder.
+		Update(tableLinks).
+		Set("deleted_at", sq.Expr("NOW()")).
+		Set("alias", nil).
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		Suffix("RETURNING id").
 		ToSql()
 
-	var res model.Link
-	if err := r.pool.QueryRow(ctx, query, args...).Scan(&res.Url, &res.CreatedAt); err != nil {
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		return handleDBError(err)
+	}
+	return nil
+}
+
+func (r *Repo) scanLink(row pgx.Row) (model.Link, error) {
+	var (
+		id  int64
+		res model.Link
+	)
+	if err := row.Scan(&id, &res.Url, &res.Alias, &res.CreatedAt, &res.ExpiresAt, &res.OwnerID); err != nil {
 		return model.Link{}, handleDBError(err)
 	}
-	res.Code = code
+	res.ID = id
+	res.Code = r.codec.Encode(id)
 
 	return res, nil
 }
@@ -77,5 +186,14 @@ func handleDBError(err error) error {
 	if errors.Is(err, pgx.ErrNoRows) {
 		return model.ErrCodeNotFound
 	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		switch pgErr.ConstraintName {
+		case aliasConstraint:
+			return model.ErrAliasTaken
+		case urlConstraint:
+			return model.ErrLinkExists
+		}
+	}
 	return err
 }
@@ -0,0 +1,319 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+//
+// Generated by this command:
+//
+//	mockgen -source contract.go -package link_test -destination mocks_test.go
+//
+
+// Package link_test is a generated GoMock package.
+package link_test
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	model "github.com/domovonok/url-shortener/internal/model"
+	pgx "github.com/jackc/pgx/v5"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockdbPool is a mock of dbPool interface.
+type MockdbPool struct {
+	ctrl     *gomock.Controller
+	recorder *MockdbPoolMockRecorder
+	isgomock struct{}
+}
+
+// MockdbPoolMockRecorder is the mock recorder for MockdbPool.
+type MockdbPoolMockRecorder struct {
+	mock *MockdbPool
+}
+
+// NewMockdbPool creates a new mock instance.
+func NewMockdbPool(ctrl *gomock.Controller) *MockdbPool {
+	mock := &MockdbPool{ctrl: ctrl}
+	mock.recorder = &MockdbPoolMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdbPool) EXPECT() *MockdbPoolMockRecorder {
+	return m.recorder
+}
+
+// QueryRow mocks base method.
+func (m *MockdbPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, sql}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryRow", varargs...)
+	ret0, _ := ret[0].(pgx.Row)
+	return ret0
+}
+
+// QueryRow indicates an expected call of QueryRow.
+func (mr *MockdbPoolMockRecorder) QueryRow(ctx, sql any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, sql}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRow", reflect.TypeOf((*MockdbPool)(nil).QueryRow), varargs...)
+}
+
+// Mockcache is a mock of cache interface.
+type Mockcache struct {
+	ctrl     *gomock.Controller
+	recorder *MockcacheMockRecorder
+	isgomock struct{}
+}
+
+// MockcacheMockRecorder is the mock recorder for Mockcache.
+type MockcacheMockRecorder struct {
+	mock *Mockcache
+}
+
+// NewMockcache creates a new mock instance.
+func NewMockcache(ctrl *gomock.Controller) *Mockcache {
+	mock := &Mockcache{ctrl: ctrl}
+	mock.recorder = &MockcacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockcache) EXPECT() *MockcacheMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *Mockcache) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockcacheMockRecorder) Delete(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*Mockcache)(nil).Delete), ctx, key)
+}
+
+// Get mocks base method.
+func (m *Mockcache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockcacheMockRecorder) Get(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*Mockcache)(nil).Get), ctx, key)
+}
+
+// Set mocks base method.
+func (m *Mockcache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, key, value, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockcacheMockRecorder) Set(ctx, key, value, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*Mockcache)(nil).Set), ctx, key, value, ttl)
+}
+
+// MocklocalCache is a mock of localCache interface.
+type MocklocalCache struct {
+	ctrl     *gomock.Controller
+	recorder *MocklocalCacheMockRecorder
+	isgomock struct{}
+}
+
+// MocklocalCacheMockRecorder is the mock recorder for MocklocalCache.
+type MocklocalCacheMockRecorder struct {
+	mock *MocklocalCache
+}
+
+// NewMocklocalCache creates a new mock instance.
+func NewMocklocalCache(ctrl *gomock.Controller) *MocklocalCache {
+	mock := &MocklocalCache{ctrl: ctrl}
+	mock.recorder = &MocklocalCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocklocalCache) EXPECT() *MocklocalCacheMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MocklocalCache) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MocklocalCacheMockRecorder) Delete(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MocklocalCache)(nil).Delete), ctx, key)
+}
+
+// Get mocks base method.
+func (m *MocklocalCache) Get(ctx context.Context, key string) (model.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(model.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MocklocalCacheMockRecorder) Get(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MocklocalCache)(nil).Get), ctx, key)
+}
+
+// Set mocks base method.
+func (m *MocklocalCache) Set(ctx context.Context, key string, value model.Link, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, key, value, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MocklocalCacheMockRecorder) Set(ctx, key, value, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MocklocalCache)(nil).Set), ctx, key, value, ttl)
+}
+
+// Mockpublisher is a mock of publisher interface.
+type Mockpublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockpublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockpublisherMockRecorder is the mock recorder for Mockpublisher.
+type MockpublisherMockRecorder struct {
+	mock *Mockpublisher
+}
+
+// NewMockpublisher creates a new mock instance.
+func NewMockpublisher(ctrl *gomock.Controller) *Mockpublisher {
+	mock := &Mockpublisher{ctrl: ctrl}
+	mock.recorder = &MockpublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockpublisher) EXPECT() *MockpublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *Mockpublisher) Publish(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockpublisherMockRecorder) Publish(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*Mockpublisher)(nil).Publish), ctx, key)
+}
+
+// MockbaseRepo is a mock of baseRepo interface.
+type MockbaseRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockbaseRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockbaseRepoMockRecorder is the mock recorder for MockbaseRepo.
+type MockbaseRepoMockRecorder struct {
+	mock *MockbaseRepo
+}
+
+// NewMockbaseRepo creates a new mock instance.
+func NewMockbaseRepo(ctrl *gomock.Controller) *MockbaseRepo {
+	mock := &MockbaseRepo{ctrl: ctrl}
+	mock.recorder = &MockbaseRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockbaseRepo) EXPECT() *MockbaseRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockbaseRepo) Create(ctx context.Context, l model.Link) (model.Link, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, l)
+	ret0, _ := ret[0].(model.Link)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockbaseRepoMockRecorder) Create(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockbaseRepo)(nil).Create), ctx, l)
+}
+
+// Delete mocks base method.
+func (m *MockbaseRepo) Delete(ctx context.Context, l model.Link) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, l)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockbaseRepoMockRecorder) Delete(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockbaseRepo)(nil).Delete), ctx, l)
+}
+
+// Get mocks base method.
+func (m *MockbaseRepo) Get(ctx context.Context, code string) (model.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, code)
+	ret0, _ := ret[0].(model.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockbaseRepoMockRecorder) Get(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockbaseRepo)(nil).Get), ctx, code)
+}
+
+// Update mocks base method.
+func (m *MockbaseRepo) Update(ctx context.Context, l model.Link) (model.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, l)
+	ret0, _ := ret[0].(model.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockbaseRepoMockRecorder) Update(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockbaseRepo)(nil).Update), ctx, l)
+}
@@ -0,0 +1,21 @@
+package requestid_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/requestid"
+)
+
+func TestValid(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, requestid.Valid("3f2a9c1e-7b1d-4c2e-9a51-0d6c2f1b8e4a"))
+	require.True(t, requestid.Valid(requestid.New()))
+	require.False(t, requestid.Valid(""))
+	require.False(t, requestid.Valid("with space"))
+	require.False(t, requestid.Valid("line\nbreak"))
+	require.False(t, requestid.Valid(strings.Repeat("a", 129)))
+}
@@ -1,14 +1,36 @@
 package router
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+	"net/netip"
+
+	"github.com/domovonok/url-shortener/internal/limiter"
+)
 
 type LinkHandler interface {
 	Create(w http.ResponseWriter, r *http.Request)
 	Get(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	Stats(w http.ResponseWriter, r *http.Request)
+}
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, key string) (int64, error)
+}
+
+type IPResolver interface {
+	Resolve(r *http.Request) netip.Addr
+}
+
+type IPFilter interface {
+	Allowed(group string, addr netip.Addr) bool
 }
 
-type TokenBucket interface {
-	Allow() bool
-	Capacity() int
-	Remaining() int
+type RateLimiter interface {
+	Take(key string) limiter.Decision
+	Capacity(key string) int
+	Peek(key string) limiter.Decision
+	RefillRate(key string) int
 }
@@ -1,27 +1,76 @@
 package router
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/domovonok/url-shortener/internal/ipfilter"
+	"github.com/domovonok/url-shortener/internal/limiter"
 	"github.com/domovonok/url-shortener/internal/logger"
 	"github.com/domovonok/url-shortener/internal/metrics"
 	"github.com/domovonok/url-shortener/internal/middleware"
 	"github.com/domovonok/url-shortener/internal/transport/http/common"
 )
 
-func New(linkHandler LinkHandler, tokenBucket TokenBucket, log logger.Logger, prom *metrics.PrometheusMetrics) *chi.Mux {
+func New(
+	linkHandler LinkHandler,
+	authenticator Authenticator,
+	ipResolver IPResolver,
+	ipFilter IPFilter,
+	rateLimiters map[string]RateLimiter,
+	log logger.Logger,
+	prom *metrics.PrometheusMetrics,
+) *chi.Mux {
 	r := chi.NewRouter()
 
+	// Requests are limited per route group after logging and metrics, so that
+	// throttled requests are observed as well.
+	limit := func(policy string, keyFunc middleware.KeyFunc) func(http.Handler) http.Handler {
+		rl, ok := rateLimiters[policy]
+		if !ok {
+			return func(next http.Handler) http.Handler { return next }
+		}
+		return middleware.RateLimitMiddleware(policy, rl, keyFunc, log, prom)
+	}
+
+	r.Use(middleware.Tracing)
+	r.Use(middleware.RequestID)
 	r.Use(middleware.Recoverer(log))
-	r.Use(middleware.RateLimitMiddleware(tokenBucket, log, prom))
+	r.Use(middleware.ClientIP(ipResolver))
 	r.Use(middleware.Logger(log))
 	r.Use(middleware.Prometheus(prom))
-	r.Handle("/metrics", promhttp.Handler())
+	r.With(middleware.IPFilter(ipfilter.GroupMetrics, ipFilter, log, prom)).Handle("/metrics", promhttp.Handler())
 
 	r.Head("/healthcheck", common.Healthcheck)
-	r.Post("/", linkHandler.Create)
-	r.Get("/{code}", linkHandler.Get)
+	r.With(limit(limiter.PolicyRedirect, middleware.IPKey)).Get("/{code}", linkHandler.Get)
+
+	// API keys are only resolved on routes that need an owner, after the IP
+	// filter and the limit for anonymous clients have had their say. Clients
+	// presenting a key are limited per owner once the key is verified.
+	var authFailures RateLimiter
+	if rl, ok := rateLimiters[limiter.PolicyAuth]; ok {
+		authFailures = rl
+	}
+	authenticate := middleware.Auth(authenticator, authFailures, log, prom)
+
+	r.With(
+		middleware.IPFilter(ipfilter.GroupCreate, ipFilter, log, prom),
+		limit(limiter.PolicyCreate, middleware.AnonymousKey),
+		authenticate,
+		limit(limiter.PolicyCreate, middleware.OwnerKey),
+	).Post("/", linkHandler.Create)
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.IPFilter(ipfilter.GroupAPI, ipFilter, log, prom))
+		r.Use(limit(limiter.PolicyAPI, middleware.AnonymousKey))
+		r.Use(authenticate)
+		r.Use(limit(limiter.PolicyAPI, middleware.OwnerKey))
+		r.Patch("/api/links/{code}", linkHandler.Update)
+		r.Delete("/api/links/{code}", linkHandler.Delete)
+		r.Get("/api/links/{code}/stats", linkHandler.Stats)
+	})
 
 	return r
 }
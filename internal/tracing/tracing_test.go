@@ -0,0 +1,38 @@
+package tracing_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/tracing"
+)
+
+func TestInitStdoutToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+
+	shutdown, err := tracing.Init(context.Background(), config.TracingConfig{
+		Exporter:    tracing.ExporterStdout,
+		File:        path,
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"Name":"test-span"`)
+}
+
+func TestInitRejectsUnknownExporter(t *testing.T) {
+	_, err := tracing.Init(context.Background(), config.TracingConfig{Exporter: "jaeger"})
+	require.Error(t, err)
+}
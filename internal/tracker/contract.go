@@ -0,0 +1,12 @@
+//go:generate mockgen -source ${GOFILE} -package ${GOPACKAGE}_test -destination mocks_test.go
+package tracker
+
+import (
+	"context"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+type clickRepo interface {
+	Insert(ctx context.Context, clicks []model.Click) error
+}
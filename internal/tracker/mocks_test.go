@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+//
+// Generated by this command:
+//
+//	mockgen -source contract.go -package tracker_test -destination mocks_test.go
+//
+
+// Package tracker_test is a generated GoMock package.
+package tracker_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/domovonok/url-shortener/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockclickRepo is a mock of clickRepo interface.
+type MockclickRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockclickRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockclickRepoMockRecorder is the mock recorder for MockclickRepo.
+type MockclickRepoMockRecorder struct {
+	mock *MockclickRepo
+}
+
+// NewMockclickRepo creates a new mock instance.
+func NewMockclickRepo(ctrl *gomock.Controller) *MockclickRepo {
+	mock := &MockclickRepo{ctrl: ctrl}
+	mock.recorder = &MockclickRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockclickRepo) EXPECT() *MockclickRepoMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockclickRepo) Insert(ctx context.Context, clicks []model.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, clicks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockclickRepoMockRecorder) Insert(ctx, clicks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockclickRepo)(nil).Insert), ctx, clicks)
+}
@@ -0,0 +1,114 @@
+package tracker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+// Tracker buffers click events and writes them to the database in batches
+// from a single background worker, so recording a click never blocks a
+// redirect. Events that do not fit into the buffer are dropped.
+type Tracker struct {
+	repo          clickRepo
+	events        chan model.Click
+	batchSize     int
+	flushInterval time.Duration
+	writeTimeout  time.Duration
+	stop          chan struct{}
+	stopOnce      sync.Once
+	done          chan struct{}
+	m             *metrics.PrometheusMetrics
+	log           logger.Logger
+}
+
+func New(repo clickRepo, cfg config.ClicksConfig, m *metrics.PrometheusMetrics, log logger.Logger) *Tracker {
+	return &Tracker{
+		repo:          repo,
+		events:        make(chan model.Click, cfg.BufferSize),
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+		writeTimeout:  cfg.WriteTimeout,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		m:             m,
+		log:           log,
+	}
+}
+
+func (t *Tracker) Track(c model.Click) {
+	select {
+	case t.events <- c:
+	default:
+		t.m.ClickEventsDroppedTotal.Inc()
+	}
+}
+
+func (t *Tracker) Run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.Click, 0, t.batchSize)
+	add := func(c model.Click) {
+		batch = append(batch, c)
+		if len(batch) >= t.batchSize {
+			t.flush(batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case c := <-t.events:
+			add(c)
+		case <-ticker.C:
+			t.flush(batch)
+			batch = batch[:0]
+		case <-t.stop:
+			for {
+				select {
+				case c := <-t.events:
+					add(c)
+				default:
+					t.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// Close stops the worker after it has written everything buffered so far.
+// It may be called more than once.
+func (t *Tracker) Close(ctx context.Context) error {
+	t.stopOnce.Do(func() { close(t.stop) })
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Tracker) flush(batch []model.Click) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.writeTimeout)
+	defer cancel()
+
+	if err := t.repo.Insert(ctx, batch); err != nil {
+		t.m.ClickEventsWriteErrorsTotal.Inc()
+		t.log.Error("Unable to write click events", logger.Any("count", len(batch)), logger.Error(err))
+		return
+	}
+	t.m.ClickEventsWrittenTotal.Add(float64(len(batch)))
+}
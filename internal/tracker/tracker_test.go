@@ -0,0 +1,87 @@
+package tracker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/logger"
+	"github.com/domovonok/url-shortener/internal/metrics"
+	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/tracker"
+)
+
+func newMetrics() *metrics.PrometheusMetrics {
+	return &metrics.PrometheusMetrics{
+		ClickEventsWrittenTotal:     prometheus.NewCounter(prometheus.CounterOpts{Name: "written"}),
+		ClickEventsDroppedTotal:     prometheus.NewCounter(prometheus.CounterOpts{Name: "dropped"}),
+		ClickEventsWriteErrorsTotal: prometheus.NewCounter(prometheus.CounterOpts{Name: "errors"}),
+	}
+}
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("flushes batches and remainder on close", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := NewMockclickRepo(ctrl)
+		m := newMetrics()
+		tr := tracker.New(repo, config.ClicksConfig{
+			BufferSize:    10,
+			BatchSize:     2,
+			FlushInterval: time.Hour,
+			WriteTimeout:  time.Second,
+		}, m, logger.MustInit(false))
+
+		var written []model.Click
+		repo.EXPECT().
+			Insert(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, clicks []model.Click) error {
+				written = append(written, clicks...)
+				return nil
+			}).
+			Times(2)
+
+		for id := range int64(3) {
+			tr.Track(model.Click{LinkID: id + 1})
+		}
+
+		go tr.Run()
+		require.NoError(t, tr.Close(context.Background()))
+		require.NoError(t, tr.Close(context.Background()), "closing twice is harmless")
+
+		require.Len(t, written, 3)
+		require.Equal(t, float64(3), testutil.ToFloat64(m.ClickEventsWrittenTotal))
+	})
+
+	t.Run("drops events when buffer is full", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := NewMockclickRepo(ctrl)
+		m := newMetrics()
+		tr := tracker.New(repo, config.ClicksConfig{
+			BufferSize:    1,
+			BatchSize:     10,
+			FlushInterval: time.Hour,
+			WriteTimeout:  time.Second,
+		}, m, logger.MustInit(false))
+
+		tr.Track(model.Click{LinkID: 1})
+		tr.Track(model.Click{LinkID: 2})
+
+		require.Equal(t, float64(1), testutil.ToFloat64(m.ClickEventsDroppedTotal))
+	})
+}
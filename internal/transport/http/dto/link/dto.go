@@ -1,9 +1,110 @@
 package link
 
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
 type CreateRequest struct {
+	Url       string     `json:"url"`
+	Alias     string     `json:"alias,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Ttl       string     `json:"ttl,omitempty"`
+}
+
+type LinkResponse struct {
+	Code      string     `json:"code"`
+	Alias     string     `json:"alias,omitempty"`
+	Url       string     `json:"url"`
+	ShortUrl  string     `json:"short_url"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type CreateResponse struct {
+	LinkResponse
+	Existing bool `json:"existing"`
+}
+
+type UpdateRequest struct {
 	Url string `json:"url"`
 }
 
 type GetRequest struct {
 	Code string `json:"code"`
 }
+
+type StatsResponse struct {
+	Code           string           `json:"code"`
+	TotalClicks    int64            `json:"total_clicks"`
+	UniqueVisitors int64            `json:"unique_visitors"`
+	FirstClick     *time.Time       `json:"first_click"`
+	LastClick      *time.Time       `json:"last_click"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	Interval       string           `json:"interval"`
+	Series         []StatsBucket    `json:"series"`
+	TopReferrers   []ReferrerCount  `json:"top_referrers"`
+	TopUserAgents  []UserAgentCount `json:"top_user_agents"`
+}
+
+type StatsBucket struct {
+	Start  time.Time `json:"start"`
+	Clicks int64     `json:"clicks"`
+}
+
+type ReferrerCount struct {
+	Referrer string `json:"referrer"`
+	Clicks   int64  `json:"clicks"`
+}
+
+type UserAgentCount struct {
+	Family string `json:"family"`
+	Clicks int64  `json:"clicks"`
+}
+
+// NewLinkResponse describes l with a short URL under baseURL. Links with an
+// alias are advertised by their alias.
+func NewLinkResponse(l model.Link, baseURL string) LinkResponse {
+	path := l.Code
+	if l.Alias != "" {
+		path = l.Alias
+	}
+	return LinkResponse{
+		Code:      l.Code,
+		Alias:     l.Alias,
+		Url:       l.Url,
+		ShortUrl:  strings.TrimSuffix(baseURL, "/") + "/" + url.PathEscape(path),
+		CreatedAt: l.CreatedAt,
+		ExpiresAt: l.ExpiresAt,
+	}
+}
+
+func NewStatsResponse(s model.LinkStats) StatsResponse {
+	res := StatsResponse{
+		Code:           s.Code,
+		TotalClicks:    s.TotalClicks,
+		UniqueVisitors: s.UniqueVisitors,
+		FirstClick:     s.FirstClick,
+		LastClick:      s.LastClick,
+		From:           s.Query.From,
+		To:             s.Query.To,
+		Interval:       s.Query.Interval,
+		Series:         make([]StatsBucket, 0, len(s.Series)),
+		TopReferrers:   make([]ReferrerCount, 0, len(s.TopReferrers)),
+		TopUserAgents:  make([]UserAgentCount, 0, len(s.TopUserAgents)),
+	}
+	for _, b := range s.Series {
+		res.Series = append(res.Series, StatsBucket{Start: b.Start, Clicks: b.Clicks})
+	}
+	for _, c := range s.TopReferrers {
+		res.TopReferrers = append(res.TopReferrers, ReferrerCount{Referrer: c.Value, Clicks: c.Clicks})
+	}
+	for _, c := range s.TopUserAgents {
+		res.TopUserAgents = append(res.TopUserAgents, UserAgentCount{Family: c.Value, Clicks: c.Clicks})
+	}
+	return res
+}
@@ -7,9 +7,25 @@ import (
 )
 
 type createUsecase interface {
-	Create(ctx context.Context, url string) (model.Link, error)
+	Create(ctx context.Context, l model.Link) (model.Link, bool, error)
 }
 
 type getUsecase interface {
 	Get(ctx context.Context, code string) (model.Link, error)
 }
+
+type updateUsecase interface {
+	Update(ctx context.Context, ownerID int64, code, url string) (model.Link, error)
+}
+
+type deleteUsecase interface {
+	Delete(ctx context.Context, ownerID int64, code string) error
+}
+
+type statsUsecase interface {
+	Stats(ctx context.Context, ownerID int64, code string, q model.StatsQuery) (model.LinkStats, error)
+}
+
+type clickTracker interface {
+	Track(c model.Click)
+}
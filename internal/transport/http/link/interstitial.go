@@ -0,0 +1,31 @@
+package link
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// The warning shown instead of redirecting to a blocklisted destination. The
+// destination itself is deliberately not linked.
+var interstitial = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Warning: suspicious link</title>
+</head>
+<body>
+<h1>This link has been blocked</h1>
+<p>The short link <code>/{{.}}</code> points to a site reported for phishing or malware, so we did not send you there.</p>
+<p>If you were asked to enter passwords, payment details or to download software, do not continue.</p>
+</body>
+</html>
+`))
+
+func writeInterstitial(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusForbidden)
+	_ = interstitial.Execute(w, code)
+}
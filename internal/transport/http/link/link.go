@@ -4,62 +4,219 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/domovonok/url-shortener/internal/auth"
+	"github.com/domovonok/url-shortener/internal/clientip"
 	"github.com/domovonok/url-shortener/internal/logger"
 	"github.com/domovonok/url-shortener/internal/model"
 	"github.com/domovonok/url-shortener/internal/transport/http/dto/link"
+	"github.com/domovonok/url-shortener/internal/transport/http/problem"
 )
 
+// maxBodyBytes bounds JSON request bodies; a valid body is far smaller since
+// destinations are limited to destination.MaxURLLength.
+const maxBodyBytes = 16 << 10
+
 type Controller struct {
 	create createUsecase
 	get    getUsecase
-	log    logger.Logger
+	update updateUsecase
+	remove deleteUsecase
+	stats  statsUsecase
+	clicks clickTracker
+	// baseURL is the public origin short links are served from.
+	baseURL string
+	log     logger.Logger
 }
 
-func New(c createUsecase, g getUsecase, l logger.Logger) *Controller {
-	return &Controller{create: c, get: g, log: l}
+func New(
+	c createUsecase,
+	g getUsecase,
+	u updateUsecase,
+	d deleteUsecase,
+	s statsUsecase,
+	t clickTracker,
+	baseURL string,
+	l logger.Logger,
+) *Controller {
+	return &Controller{create: c, get: g, update: u, remove: d, stats: s, clicks: t, baseURL: baseURL, log: l}
 }
 
 func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 	var req link.CreateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		c.responseError(w, model.ErrInvalidInput)
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+		c.responseError(w, r, model.ErrInvalidInput)
+		return
+	}
+
+	expiresAt, err := expiration(req)
+	if err != nil {
+		c.responseError(w, r, err)
 		return
 	}
 
-	res, err := c.create.Create(r.Context(), req.Url)
+	ownerID, _ := auth.OwnerFromContext(r.Context())
+
+	res, created, err := c.create.Create(r.Context(), model.Link{
+		Url:       req.Url,
+		Alias:     req.Alias,
+		ExpiresAt: expiresAt,
+		OwnerID:   ownerID,
+	})
 	if err != nil {
-		c.responseError(w, err)
+		c.responseError(w, r, err)
 		return
 	}
 
+	resp := link.CreateResponse{
+		LinkResponse: link.NewLinkResponse(res, c.baseURL),
+		Existing:     !created,
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		w.Header().Set("Location", resp.ShortUrl)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(res)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 func (c *Controller) Get(w http.ResponseWriter, r *http.Request) {
 	code := chi.URLParam(r, "code")
 
 	res, err := c.get.Get(r.Context(), code)
+	if errors.Is(err, model.ErrDestinationBlocked) {
+		writeInterstitial(w, code)
+		return
+	}
+	if err != nil {
+		c.responseError(w, r, err)
+		return
+	}
+
+	c.clicks.Track(model.Click{
+		LinkID:    res.ID,
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+		IP:        clientIP(r),
+		ClickedAt: time.Now(),
+	})
+
+	// A permanent or cacheable redirect would keep clients away from us, so
+	// expiry, edits, deletion and the blocklist would never reach them and
+	// repeat clicks would go uncounted.
+	w.Header().Set("Cache-Control", "private, max-age=0")
+	http.Redirect(w, r, res.Url, http.StatusFound)
+}
+
+func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+
+	var req link.UpdateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+		c.responseError(w, r, model.ErrInvalidInput)
+		return
+	}
+
+	ownerID, _ := auth.OwnerFromContext(r.Context())
+
+	res, err := c.update.Update(r.Context(), ownerID, code, req.Url)
+	if err != nil {
+		c.responseError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(link.NewLinkResponse(res, c.baseURL))
+}
+
+func (c *Controller) Delete(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	ownerID, _ := auth.OwnerFromContext(r.Context())
+
+	if err := c.remove.Delete(r.Context(), ownerID, code); err != nil {
+		c.responseError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *Controller) Stats(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+
+	q, err := statsQuery(r)
+	if err != nil {
+		c.responseError(w, r, err)
+		return
+	}
+
+	ownerID, _ := auth.OwnerFromContext(r.Context())
+
+	res, err := c.stats.Stats(r.Context(), ownerID, code, q)
 	if err != nil {
-		c.responseError(w, err)
+		c.responseError(w, r, err)
 		return
 	}
 
-	http.Redirect(w, r, res.Url, http.StatusMovedPermanently)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(link.NewStatsResponse(res))
+}
+
+func statsQuery(r *http.Request) (model.StatsQuery, error) {
+	params := r.URL.Query()
+	q := model.StatsQuery{Interval: params.Get("interval")}
+
+	for param, dst := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		v := params.Get(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return model.StatsQuery{}, model.NewValidationError(model.ErrInvalidInput, param, "must be an RFC 3339 timestamp")
+		}
+		*dst = t
+	}
+
+	return q, nil
+}
+
+func clientIP(r *http.Request) string {
+	if addr, ok := clientip.FromContext(r.Context()); ok {
+		return addr.String()
+	}
+	return ""
+}
+
+func expiration(req link.CreateRequest) (*time.Time, error) {
+	if req.Ttl == "" {
+		return req.ExpiresAt, nil
+	}
+	if req.ExpiresAt != nil {
+		return nil, model.NewValidationError(model.ErrInvalidExpiry, "ttl", "cannot be combined with expires_at")
+	}
+
+	ttl, err := time.ParseDuration(req.Ttl)
+	if err != nil || ttl <= 0 {
+		return nil, model.NewValidationError(model.ErrInvalidExpiry, "ttl", "must be a positive duration")
+	}
+	expiresAt := time.Now().Add(ttl)
+	return &expiresAt, nil
 }
 
-func (c *Controller) responseError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, model.ErrInvalidInput):
-		http.Error(w, `{"error": "Invalid Input"}`, http.StatusBadRequest)
-	case errors.Is(err, model.ErrCodeNotFound):
-		http.Error(w, `{"error": "Code Not Found"}`, http.StatusBadRequest)
-	default:
-		c.log.Error("Internal error", logger.Error(err))
-		http.Error(w, `{"error": "Internal Server Error"}`, http.StatusInternalServerError)
+func (c *Controller) responseError(w http.ResponseWriter, r *http.Request, err error) {
+	p := problem.FromError(err)
+	if p.Status == http.StatusInternalServerError {
+		c.log.WithContext(r.Context()).Error("Internal error", logger.Error(err))
 	}
+	problem.Write(w, r, p)
 }
@@ -0,0 +1,142 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/requestid"
+)
+
+const (
+	ContentType = "application/problem+json"
+
+	typePrefix = "urn:url-shortener:problem:"
+)
+
+// Kind is a class of problem with a stable type URI.
+type Kind struct {
+	slug   string
+	title  string
+	status int
+}
+
+func (k Kind) Type() string {
+	return typePrefix + k.slug
+}
+
+func (k Kind) Status() int {
+	return k.status
+}
+
+var (
+	MalformedRequest   = Kind{"malformed-request", "Malformed Request", http.StatusBadRequest}
+	ValidationFailed   = Kind{"validation-failed", "Validation Failed", http.StatusUnprocessableEntity}
+	InvalidURL         = Kind{"invalid-url", "Invalid URL", http.StatusUnprocessableEntity}
+	DestinationDenied  = Kind{"destination-denied", "Destination Not Allowed", http.StatusUnprocessableEntity}
+	DestinationBlocked = Kind{"destination-blocked", "Destination Blocked", http.StatusUnprocessableEntity}
+	InvalidAlias       = Kind{"invalid-alias", "Invalid Alias", http.StatusUnprocessableEntity}
+	InvalidExpiry      = Kind{"invalid-expiration", "Invalid Expiration", http.StatusUnprocessableEntity}
+	Unauthorized       = Kind{"unauthorized", "Unauthorized", http.StatusUnauthorized}
+	Forbidden          = Kind{"forbidden", "Forbidden", http.StatusForbidden}
+	NotFound           = Kind{"not-found", "Code Not Found", http.StatusNotFound}
+	AliasTaken         = Kind{"alias-taken", "Alias Already Taken", http.StatusConflict}
+	AliasConflict      = Kind{"alias-conflict", "Link Already Has Another Alias", http.StatusConflict}
+	LinkExists         = Kind{"link-exists", "Link Already Exists", http.StatusConflict}
+	LinkExpired        = Kind{"link-expired", "Link Expired", http.StatusGone}
+	RateLimited        = Kind{"rate-limited", "Rate Limit Exceeded", http.StatusTooManyRequests}
+	Internal           = Kind{"internal", "Internal Server Error", http.StatusInternalServerError}
+
+	// DestinationUnresolved is worth retrying, unlike a host that does not
+	// exist.
+	DestinationUnresolved = Kind{"destination-unresolved", "Destination Host Could Not Be Resolved", http.StatusServiceUnavailable}
+)
+
+var kinds = []struct {
+	err  error
+	kind Kind
+}{
+	{model.ErrInvalidInput, MalformedRequest},
+	{model.ErrInvalidURL, InvalidURL},
+	{model.ErrDestinationDenied, DestinationDenied},
+	{model.ErrDestinationBlocked, DestinationBlocked},
+	{model.ErrDestinationUnresolved, DestinationUnresolved},
+	{model.ErrInvalidAlias, InvalidAlias},
+	{model.ErrInvalidExpiry, InvalidExpiry},
+	{model.ErrUnauthorized, Unauthorized},
+	{model.ErrForbidden, Forbidden},
+	{model.ErrCodeNotFound, NotFound},
+	{model.ErrAliasTaken, AliasTaken},
+	{model.ErrAliasConflict, AliasConflict},
+	{model.ErrLinkExists, LinkExists},
+	{model.ErrLinkExpired, LinkExpired},
+}
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func New(k Kind, detail string) Problem {
+	return Problem{Type: k.Type(), Title: k.title, Status: k.status, Detail: detail}
+}
+
+// FromError maps a domain error to a problem. Validation errors carry their
+// field details; well-formed input that fails validation is reported as 422.
+// Unknown errors become a 500 without exposing their message.
+func FromError(err error) Problem {
+	kind, ok := lookup(err)
+	if !ok {
+		return New(Internal, "")
+	}
+
+	var verr *model.ValidationError
+	if !errors.As(err, &verr) {
+		return New(kind, "")
+	}
+
+	if kind == MalformedRequest {
+		kind = ValidationFailed
+	}
+	p := New(kind, "")
+	for _, f := range verr.Fields {
+		p.Errors = append(p.Errors, FieldError{Field: f.Field, Reason: f.Reason})
+	}
+	return p
+}
+
+// Write sends p, filling in the request path and ID.
+func Write(w http.ResponseWriter, r *http.Request, p Problem) {
+	p.Instance = r.URL.Path
+	p.RequestID, _ = requestid.FromContext(r.Context())
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// WriteError sends the problem err maps to.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	Write(w, r, FromError(err))
+}
+
+func lookup(err error) (Kind, bool) {
+	for _, k := range kinds {
+		if errors.Is(err, k.err) {
+			return k.kind, true
+		}
+	}
+	return Kind{}, false
+}
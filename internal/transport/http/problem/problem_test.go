@@ -0,0 +1,65 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/requestid"
+	"github.com/domovonok/url-shortener/internal/transport/http/problem"
+)
+
+func TestFromError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err    error
+		status int
+		typ    string
+	}{
+		{model.ErrInvalidInput, http.StatusBadRequest, "urn:url-shortener:problem:malformed-request"},
+		{model.NewValidationError(model.ErrInvalidInput, "url", "is required"), http.StatusUnprocessableEntity, "urn:url-shortener:problem:validation-failed"},
+		{model.NewValidationError(model.ErrInvalidAlias, "alias", "is reserved"), http.StatusUnprocessableEntity, "urn:url-shortener:problem:invalid-alias"},
+		{model.NewValidationError(model.ErrInvalidURL, "url", "must have a host"), http.StatusUnprocessableEntity, "urn:url-shortener:problem:invalid-url"},
+		{model.NewValidationError(model.ErrDestinationDenied, "url", "points to a private network"), http.StatusUnprocessableEntity, "urn:url-shortener:problem:destination-denied"},
+		{model.NewValidationError(model.ErrDestinationBlocked, "url", "is on the blocklist"), http.StatusUnprocessableEntity, "urn:url-shortener:problem:destination-blocked"},
+		{fmt.Errorf("%w: i/o timeout", model.ErrDestinationUnresolved), http.StatusServiceUnavailable, "urn:url-shortener:problem:destination-unresolved"},
+		{fmt.Errorf("lookup: %w", model.ErrCodeNotFound), http.StatusNotFound, "urn:url-shortener:problem:not-found"},
+		{model.ErrLinkExists, http.StatusConflict, "urn:url-shortener:problem:link-exists"},
+		{model.ErrLinkExpired, http.StatusGone, "urn:url-shortener:problem:link-expired"},
+		{errors.New("connection reset"), http.StatusInternalServerError, "urn:url-shortener:problem:internal"},
+	}
+
+	for _, tt := range tests {
+		p := problem.FromError(tt.err)
+		require.Equal(t, tt.status, p.Status, tt.err.Error())
+		require.Equal(t, tt.typ, p.Type, tt.err.Error())
+		require.Empty(t, p.Detail, "error messages must not leak")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(requestid.WithID(r.Context(), "req-1"))
+	w := httptest.NewRecorder()
+
+	problem.WriteError(w, r, model.NewValidationError(model.ErrInvalidAlias, "alias", "is reserved"))
+
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	require.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+	var got problem.Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+	require.Equal(t, "Invalid Alias", got.Title)
+	require.Equal(t, "/", got.Instance)
+	require.Equal(t, "req-1", got.RequestID)
+	require.Equal(t, []problem.FieldError{{Field: "alias", Reason: "is reserved"}}, got.Errors)
+}
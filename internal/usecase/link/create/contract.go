@@ -8,5 +8,16 @@ import (
 )
 
 type linkRepo interface {
-	Create(ctx context.Context, url string) (model.Link, error)
+	Create(ctx context.Context, l model.Link) (model.Link, bool, error)
+}
+
+type destinationPolicy interface {
+	Check(ctx context.Context, destination string) error
+}
+
+// blocklistStage labels the blocklist hits of this usecase.
+const blocklistStage = "create"
+
+type destinationBlocklist interface {
+	Blocked(ctx context.Context, stage, destination string) bool
 }
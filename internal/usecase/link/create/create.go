@@ -2,18 +2,84 @@ package create
 
 import (
 	"context"
+	"regexp"
+	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel"
+
+	"github.com/domovonok/url-shortener/internal/destination"
 	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/tracing"
+)
+
+var (
+	tracer = otel.Tracer("github.com/domovonok/url-shortener/internal/usecase/link/create")
+
+	aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,64}$`)
+
+	// Top-level paths served by the router itself.
+	reservedAliases = map[string]struct{}{
+		"api":         {},
+		"admin":       {},
+		"debug":       {},
+		"healthcheck": {},
+		"metrics":     {},
+		"stats":       {},
+	}
 )
 
 type Usecase struct {
-	link linkRepo
+	link      linkRepo
+	policy    destinationPolicy
+	blocklist destinationBlocklist
+}
+
+func New(l linkRepo, p destinationPolicy, b destinationBlocklist) *Usecase {
+	return &Usecase{link: l, policy: p, blocklist: b}
 }
 
-func New(l linkRepo) *Usecase {
-	return &Usecase{link: l}
+// Create stores the link, or returns the owner's existing link for the same
+// URL. The flag reports whether a new link was created or an expired or
+// deleted one revived.
+func (s *Usecase) Create(ctx context.Context, l model.Link) (_ model.Link, created bool, err error) {
+	ctx, span := tracer.Start(ctx, "create.Usecase.Create")
+	defer func() {
+		tracing.End(span, err, model.ErrInvalidURL, model.ErrDestinationDenied, model.ErrDestinationBlocked,
+			model.ErrDestinationUnresolved, model.ErrInvalidAlias, model.ErrInvalidExpiry, model.ErrAliasTaken,
+			model.ErrAliasConflict)
+	}()
+
+	// The URL is canonicalized before it reaches the repo, whose dedup
+	// compares stored URLs verbatim.
+	if l.Url, err = destination.Normalize(l.Url); err != nil {
+		return model.Link{}, false, err
+	}
+	if l.Alias != "" {
+		if err := validateAlias(l.Alias); err != nil {
+			return model.Link{}, false, err
+		}
+	}
+	if l.Expired(time.Now()) {
+		return model.Link{}, false, model.NewValidationError(model.ErrInvalidExpiry, "expires_at", "must be in the future")
+	}
+	// The checks below may consult the blocklist and DNS, so they run only
+	// for requests that are otherwise valid.
+	if s.blocklist.Blocked(ctx, blocklistStage, l.Url) {
+		return model.Link{}, false, model.NewValidationError(model.ErrDestinationBlocked, "url", "is on the blocklist")
+	}
+	if err := s.policy.Check(ctx, l.Url); err != nil {
+		return model.Link{}, false, err
+	}
+	return s.link.Create(ctx, l)
 }
 
-func (s *Usecase) Create(ctx context.Context, url string) (model.Link, error) {
-	return s.link.Create(ctx, url)
+func validateAlias(alias string) error {
+	if !aliasPattern.MatchString(alias) {
+		return model.NewValidationError(model.ErrInvalidAlias, "alias", "must be 3 to 64 letters, digits, '-' or '_'")
+	}
+	if _, ok := reservedAliases[strings.ToLower(alias)]; ok {
+		return model.NewValidationError(model.ErrInvalidAlias, "alias", "is reserved")
+	}
+	return nil
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/domovonok/url-shortener/internal/model"
 	"github.com/domovonok/url-shortener/internal/usecase/link/create"
+	"github.com/domovonok/url-shortener/internal/usecase/link/linktest"
 )
 
 func TestCreate(t *testing.T) {
@@ -24,24 +25,140 @@ func TestCreate(t *testing.T) {
 
 		ctx := context.Background()
 		repo := NewMocklinkRepo(ctrl)
-		uc := create.New(repo)
+		uc := create.New(repo, linktest.Policy{}, linktest.Blocklist{})
 
-		url := "https://test.com/some/path/1"
+		in := model.Link{Url: "https://test.com/some/path/1"}
 		want := model.Link{
-			Url:       url,
+			Url:       in.Url,
 			Code:      "Code123",
 			CreatedAt: time.Unix(123, 0).UTC(),
 		}
 
 		repo.EXPECT().
-			Create(gomock.Any(), url).
-			Return(want, nil)
+			Create(gomock.Any(), in).
+			Return(want, true, nil)
 
-		got, err := uc.Create(ctx, url)
+		got, created, err := uc.Create(ctx, in)
 		require.NoError(t, err)
+		require.True(t, created)
 		require.Equal(t, want, got)
 	})
 
+	t.Run("success with alias", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		repo := NewMocklinkRepo(ctrl)
+		uc := create.New(repo, linktest.Policy{}, linktest.Blocklist{})
+
+		in := model.Link{Url: "https://test.com/some/path/1", Alias: "spring-sale"}
+		want := model.Link{
+			Url:       in.Url,
+			Code:      "Code123",
+			Alias:     in.Alias,
+			CreatedAt: time.Unix(123, 0).UTC(),
+		}
+
+		repo.EXPECT().
+			Create(gomock.Any(), in).
+			Return(want, true, nil)
+
+		got, created, err := uc.Create(ctx, in)
+		require.NoError(t, err)
+		require.True(t, created)
+		require.Equal(t, want, got)
+	})
+
+	t.Run("invalid alias", func(t *testing.T) {
+		t.Parallel()
+
+		for _, alias := range []string{"ab", "has space", "slash/alias", "Metrics", "healthcheck"} {
+			ctrl := gomock.NewController(t)
+			repo := NewMocklinkRepo(ctrl)
+			// Invalid requests are rejected before the destination is resolved.
+			uc := create.New(repo, NewMockdestinationPolicy(ctrl), NewMockdestinationBlocklist(ctrl))
+
+			got, _, err := uc.Create(context.Background(), model.Link{Url: "https://test.com", Alias: alias})
+			require.ErrorIs(t, err, model.ErrInvalidAlias, alias)
+			require.Empty(t, got)
+		}
+	})
+
+	t.Run("normalizes url", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := NewMocklinkRepo(ctrl)
+		uc := create.New(repo, linktest.Policy{}, linktest.Blocklist{})
+
+		want := model.Link{Url: "https://test.com/some/path", Code: "Code123"}
+
+		repo.EXPECT().
+			Create(gomock.Any(), model.Link{Url: "https://test.com/some/path"}).
+			Return(want, true, nil)
+
+		got, _, err := uc.Create(context.Background(), model.Link{Url: "HTTPS://Test.COM:443/some/./path"})
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		uc := create.New(NewMocklinkRepo(ctrl), linktest.Policy{}, linktest.Blocklist{})
+
+		got, _, err := uc.Create(context.Background(), model.Link{Url: "javascript:alert(1)"})
+		require.ErrorIs(t, err, model.ErrInvalidURL)
+		require.Empty(t, got)
+	})
+
+	t.Run("rejected destination", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name      string
+			policy    linktest.Policy
+			blocklist linktest.Blocklist
+			want      error
+		}{
+			{name: "denied", policy: linktest.Policy{Denied: "https://evil.example/login"}, want: model.ErrDestinationDenied},
+			{name: "blocklisted", blocklist: linktest.Blocklist{Stage: "create", URL: "https://evil.example/login"}, want: model.ErrDestinationBlocked},
+		}
+
+		for _, tt := range tests {
+			ctrl := gomock.NewController(t)
+			uc := create.New(NewMocklinkRepo(ctrl), tt.policy, tt.blocklist)
+
+			got, _, err := uc.Create(context.Background(), model.Link{Url: "https://EVIL.example/login"})
+			require.ErrorIs(t, err, tt.want, tt.name)
+			require.Empty(t, got)
+		}
+	})
+
+	t.Run("expiration in the past", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := NewMocklinkRepo(ctrl)
+		uc := create.New(repo, NewMockdestinationPolicy(ctrl), NewMockdestinationBlocklist(ctrl))
+
+		expiresAt := time.Now().Add(-time.Minute)
+
+		got, _, err := uc.Create(context.Background(), model.Link{Url: "https://test.com", ExpiresAt: &expiresAt})
+		require.ErrorIs(t, err, model.ErrInvalidExpiry)
+		require.Empty(t, got)
+	})
+
 	t.Run("error", func(t *testing.T) {
 		t.Parallel()
 
@@ -50,16 +167,16 @@ func TestCreate(t *testing.T) {
 
 		ctx := context.Background()
 		repo := NewMocklinkRepo(ctrl)
-		uc := create.New(repo)
+		uc := create.New(repo, linktest.Policy{}, linktest.Blocklist{})
 
-		url := "https://test.com/some/path/1"
+		in := model.Link{Url: "https://test.com/some/path/1"}
 		wantErr := errors.New("repo failure")
 
 		repo.EXPECT().
-			Create(gomock.Any(), url).
-			Return(model.Link{}, wantErr)
+			Create(gomock.Any(), in).
+			Return(model.Link{}, false, wantErr)
 
-		got, err := uc.Create(ctx, url)
+		got, _, err := uc.Create(ctx, in)
 		require.Error(t, err)
 		require.ErrorIs(t, wantErr, err)
 		require.Empty(t, got)
@@ -42,16 +42,93 @@ func (m *MocklinkRepo) EXPECT() *MocklinkRepoMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MocklinkRepo) Create(ctx context.Context, url string) (model.Link, error) {
+func (m *MocklinkRepo) Create(ctx context.Context, l model.Link) (model.Link, bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, url)
+	ret := m.ctrl.Call(m, "Create", ctx, l)
 	ret0, _ := ret[0].(model.Link)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Create indicates an expected call of Create.
-func (mr *MocklinkRepoMockRecorder) Create(ctx, url any) *gomock.Call {
+func (mr *MocklinkRepoMockRecorder) Create(ctx, l any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MocklinkRepo)(nil).Create), ctx, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MocklinkRepo)(nil).Create), ctx, l)
+}
+
+// MockdestinationPolicy is a mock of destinationPolicy interface.
+type MockdestinationPolicy struct {
+	ctrl     *gomock.Controller
+	recorder *MockdestinationPolicyMockRecorder
+	isgomock struct{}
+}
+
+// MockdestinationPolicyMockRecorder is the mock recorder for MockdestinationPolicy.
+type MockdestinationPolicyMockRecorder struct {
+	mock *MockdestinationPolicy
+}
+
+// NewMockdestinationPolicy creates a new mock instance.
+func NewMockdestinationPolicy(ctrl *gomock.Controller) *MockdestinationPolicy {
+	mock := &MockdestinationPolicy{ctrl: ctrl}
+	mock.recorder = &MockdestinationPolicyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdestinationPolicy) EXPECT() *MockdestinationPolicyMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockdestinationPolicy) Check(ctx context.Context, destination string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, destination)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockdestinationPolicyMockRecorder) Check(ctx, destination any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockdestinationPolicy)(nil).Check), ctx, destination)
+}
+
+// MockdestinationBlocklist is a mock of destinationBlocklist interface.
+type MockdestinationBlocklist struct {
+	ctrl     *gomock.Controller
+	recorder *MockdestinationBlocklistMockRecorder
+	isgomock struct{}
+}
+
+// MockdestinationBlocklistMockRecorder is the mock recorder for MockdestinationBlocklist.
+type MockdestinationBlocklistMockRecorder struct {
+	mock *MockdestinationBlocklist
+}
+
+// NewMockdestinationBlocklist creates a new mock instance.
+func NewMockdestinationBlocklist(ctrl *gomock.Controller) *MockdestinationBlocklist {
+	mock := &MockdestinationBlocklist{ctrl: ctrl}
+	mock.recorder = &MockdestinationBlocklistMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdestinationBlocklist) EXPECT() *MockdestinationBlocklistMockRecorder {
+	return m.recorder
+}
+
+// Blocked mocks base method.
+func (m *MockdestinationBlocklist) Blocked(ctx context.Context, stage, destination string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Blocked", ctx, stage, destination)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Blocked indicates an expected call of Blocked.
+func (mr *MockdestinationBlocklistMockRecorder) Blocked(ctx, stage, destination any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Blocked", reflect.TypeOf((*MockdestinationBlocklist)(nil).Blocked), ctx, stage, destination)
 }
@@ -10,3 +10,10 @@ import (
 type linkRepo interface {
 	Get(ctx context.Context, code string) (model.Link, error)
 }
+
+// blocklistStage labels the blocklist hits of this usecase.
+const blocklistStage = "redirect"
+
+type destinationBlocklist interface {
+	Blocked(ctx context.Context, stage, destination string) bool
+}
@@ -2,18 +2,42 @@ package get
 
 import (
 	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
 
 	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/tracing"
 )
 
+var tracer = otel.Tracer("github.com/domovonok/url-shortener/internal/usecase/link/get")
+
 type Usecase struct {
-	link linkRepo
+	link      linkRepo
+	blocklist destinationBlocklist
 }
 
-func New(l linkRepo) *Usecase {
-	return &Usecase{link: l}
+func New(l linkRepo, b destinationBlocklist) *Usecase {
+	return &Usecase{link: l, blocklist: b}
 }
 
-func (s *Usecase) Get(ctx context.Context, code string) (model.Link, error) {
-	return s.link.Get(ctx, code)
+func (s *Usecase) Get(ctx context.Context, code string) (_ model.Link, err error) {
+	ctx, span := tracer.Start(ctx, "get.Usecase.Get")
+	defer func() {
+		tracing.End(span, err, model.ErrCodeNotFound, model.ErrLinkExpired, model.ErrDestinationBlocked)
+	}()
+
+	res, err := s.link.Get(ctx, code)
+	if err != nil {
+		return model.Link{}, err
+	}
+	if res.Expired(time.Now()) {
+		return model.Link{}, model.ErrLinkExpired
+	}
+	// Checked on every redirect rather than at creation only, so that
+	// destinations flagged later stop working as soon as the list reloads.
+	if s.blocklist.Blocked(ctx, blocklistStage, res.Url) {
+		return model.Link{}, model.ErrDestinationBlocked
+	}
+	return res, nil
 }
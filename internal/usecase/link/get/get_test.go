@@ -11,6 +11,7 @@ import (
 
 	"github.com/domovonok/url-shortener/internal/model"
 	"github.com/domovonok/url-shortener/internal/usecase/link/get"
+	"github.com/domovonok/url-shortener/internal/usecase/link/linktest"
 )
 
 func TestGet(t *testing.T) {
@@ -24,7 +25,7 @@ func TestGet(t *testing.T) {
 
 		ctx := context.Background()
 		repo := NewMocklinkRepo(ctrl)
-		uc := get.New(repo)
+		uc := get.New(repo, linktest.Blocklist{})
 
 		code := "Code123"
 		want := model.Link{
@@ -42,6 +43,46 @@ func TestGet(t *testing.T) {
 		require.Equal(t, want, got)
 	})
 
+	t.Run("expired", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		repo := NewMocklinkRepo(ctrl)
+		uc := get.New(repo, linktest.Blocklist{})
+
+		code := "Code123"
+		expiresAt := time.Now().Add(-time.Minute)
+
+		repo.EXPECT().
+			Get(gomock.Any(), code).
+			Return(model.Link{Url: "https://test.com", Code: code, ExpiresAt: &expiresAt}, nil)
+
+		got, err := uc.Get(ctx, code)
+		require.ErrorIs(t, err, model.ErrLinkExpired)
+		require.Empty(t, got)
+	})
+
+	t.Run("blocklisted destination", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := NewMocklinkRepo(ctrl)
+		uc := get.New(repo, linktest.Blocklist{Stage: "redirect", URL: "https://evil.example/login"})
+
+		repo.EXPECT().
+			Get(gomock.Any(), "Code123").
+			Return(model.Link{Url: "https://evil.example/login", Code: "Code123"}, nil)
+
+		got, err := uc.Get(context.Background(), "Code123")
+		require.ErrorIs(t, err, model.ErrDestinationBlocked)
+		require.Empty(t, got)
+	})
+
 	t.Run("error", func(t *testing.T) {
 		t.Parallel()
 
@@ -50,7 +91,7 @@ func TestGet(t *testing.T) {
 
 		ctx := context.Background()
 		repo := NewMocklinkRepo(ctrl)
-		uc := get.New(repo)
+		uc := get.New(repo, linktest.Blocklist{})
 
 		code := "Code123"
 		wantErr := errors.New("repo failure")
@@ -55,3 +55,41 @@ func (mr *MocklinkRepoMockRecorder) Get(ctx, code any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MocklinkRepo)(nil).Get), ctx, code)
 }
+
+// MockdestinationBlocklist is a mock of destinationBlocklist interface.
+type MockdestinationBlocklist struct {
+	ctrl     *gomock.Controller
+	recorder *MockdestinationBlocklistMockRecorder
+	isgomock struct{}
+}
+
+// MockdestinationBlocklistMockRecorder is the mock recorder for MockdestinationBlocklist.
+type MockdestinationBlocklistMockRecorder struct {
+	mock *MockdestinationBlocklist
+}
+
+// NewMockdestinationBlocklist creates a new mock instance.
+func NewMockdestinationBlocklist(ctrl *gomock.Controller) *MockdestinationBlocklist {
+	mock := &MockdestinationBlocklist{ctrl: ctrl}
+	mock.recorder = &MockdestinationBlocklistMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdestinationBlocklist) EXPECT() *MockdestinationBlocklistMockRecorder {
+	return m.recorder
+}
+
+// Blocked mocks base method.
+func (m *MockdestinationBlocklist) Blocked(ctx context.Context, stage, destination string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Blocked", ctx, stage, destination)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Blocked indicates an expected call of Blocked.
+func (mr *MockdestinationBlocklistMockRecorder) Blocked(ctx, stage, destination any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Blocked", reflect.TypeOf((*MockdestinationBlocklist)(nil).Blocked), ctx, stage, destination)
+}
@@ -0,0 +1,32 @@
+// Package linktest provides stubs of the destination checks shared by the
+// link usecases, for tests that are not about those checks.
+package linktest
+
+import (
+	"context"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+// Policy allows every destination except Denied.
+type Policy struct {
+	Denied string
+}
+
+func (p Policy) Check(_ context.Context, destination string) error {
+	if p.Denied != "" && destination == p.Denied {
+		return model.NewValidationError(model.ErrDestinationDenied, "url", "is denied")
+	}
+	return nil
+}
+
+// Blocklist blocks URL when it is screened at Stage; the zero value blocks
+// nothing.
+type Blocklist struct {
+	Stage string
+	URL   string
+}
+
+func (b Blocklist) Blocked(_ context.Context, stage, destination string) bool {
+	return b.URL != "" && stage == b.Stage && destination == b.URL
+}
@@ -0,0 +1,13 @@
+//go:generate mockgen -source ${GOFILE} -package ${GOPACKAGE}_test -destination mocks_test.go
+package remove
+
+import (
+	"context"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+type linkRepo interface {
+	Get(ctx context.Context, code string) (model.Link, error)
+	Delete(ctx context.Context, l model.Link) error
+}
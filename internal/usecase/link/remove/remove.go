@@ -0,0 +1,27 @@
+package remove
+
+import (
+	"context"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+type Usecase struct {
+	link linkRepo
+}
+
+func New(l linkRepo) *Usecase {
+	return &Usecase{link: l}
+}
+
+func (s *Usecase) Delete(ctx context.Context, ownerID int64, code string) error {
+	l, err := s.link.Get(ctx, code)
+	if err != nil {
+		return err
+	}
+	if l.OwnerID != ownerID {
+		return model.ErrForbidden
+	}
+
+	return s.link.Delete(ctx, l)
+}
@@ -0,0 +1,75 @@
+package remove_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/usecase/link/remove"
+)
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	existing := model.Link{Url: "https://test.com", Code: "Code123", OwnerID: 7}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := NewMocklinkRepo(ctrl)
+		uc := remove.New(repo)
+
+		repo.EXPECT().
+			Get(gomock.Any(), "Code123").
+			Return(existing, nil)
+		repo.EXPECT().
+			Delete(gomock.Any(), existing).
+			Return(nil)
+
+		require.NoError(t, uc.Delete(context.Background(), 7, "Code123"))
+	})
+
+	t.Run("not the owner", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := NewMocklinkRepo(ctrl)
+		uc := remove.New(repo)
+
+		repo.EXPECT().
+			Get(gomock.Any(), "Code123").
+			Return(existing, nil)
+
+		require.ErrorIs(t, uc.Delete(context.Background(), 8, "Code123"), model.ErrForbidden)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := NewMocklinkRepo(ctrl)
+		uc := remove.New(repo)
+
+		wantErr := errors.New("repo failure")
+
+		repo.EXPECT().
+			Get(gomock.Any(), "Code123").
+			Return(existing, nil)
+		repo.EXPECT().
+			Delete(gomock.Any(), existing).
+			Return(wantErr)
+
+		require.ErrorIs(t, uc.Delete(context.Background(), 7, "Code123"), wantErr)
+	})
+}
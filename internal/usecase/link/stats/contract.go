@@ -0,0 +1,19 @@
+//go:generate mockgen -source ${GOFILE} -package ${GOPACKAGE}_test -destination mocks_test.go
+package stats
+
+import (
+	"context"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+type linkRepo interface {
+	Get(ctx context.Context, code string) (model.Link, error)
+}
+
+type clickRepo interface {
+	Summary(ctx context.Context, linkID int64, q model.StatsQuery) (model.ClickSummary, error)
+	Series(ctx context.Context, linkID int64, q model.StatsQuery) ([]model.StatsBucket, error)
+	TopReferrers(ctx context.Context, linkID int64, q model.StatsQuery, limit uint64) ([]model.StatsCount, error)
+	TopUserAgents(ctx context.Context, linkID int64, q model.StatsQuery, limit uint64) ([]model.StatsCount, error)
+}
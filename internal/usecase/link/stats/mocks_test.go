@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+//
+// Generated by this command:
+//
+//	mockgen -source contract.go -package stats_test -destination mocks_test.go
+//
+
+// Package stats_test is a generated GoMock package.
+package stats_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/domovonok/url-shortener/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MocklinkRepo is a mock of linkRepo interface.
+type MocklinkRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MocklinkRepoMockRecorder
+	isgomock struct{}
+}
+
+// MocklinkRepoMockRecorder is the mock recorder for MocklinkRepo.
+type MocklinkRepoMockRecorder struct {
+	mock *MocklinkRepo
+}
+
+// NewMocklinkRepo creates a new mock instance.
+func NewMocklinkRepo(ctrl *gomock.Controller) *MocklinkRepo {
+	mock := &MocklinkRepo{ctrl: ctrl}
+	mock.recorder = &MocklinkRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocklinkRepo) EXPECT() *MocklinkRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MocklinkRepo) Get(ctx context.Context, code string) (model.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, code)
+	ret0, _ := ret[0].(model.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MocklinkRepoMockRecorder) Get(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MocklinkRepo)(nil).Get), ctx, code)
+}
+
+// MockclickRepo is a mock of clickRepo interface.
+type MockclickRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockclickRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockclickRepoMockRecorder is the mock recorder for MockclickRepo.
+type MockclickRepoMockRecorder struct {
+	mock *MockclickRepo
+}
+
+// NewMockclickRepo creates a new mock instance.
+func NewMockclickRepo(ctrl *gomock.Controller) *MockclickRepo {
+	mock := &MockclickRepo{ctrl: ctrl}
+	mock.recorder = &MockclickRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockclickRepo) EXPECT() *MockclickRepoMockRecorder {
+	return m.recorder
+}
+
+// Series mocks base method.
+func (m *MockclickRepo) Series(ctx context.Context, linkID int64, q model.StatsQuery) ([]model.StatsBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Series", ctx, linkID, q)
+	ret0, _ := ret[0].([]model.StatsBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Series indicates an expected call of Series.
+func (mr *MockclickRepoMockRecorder) Series(ctx, linkID, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Series", reflect.TypeOf((*MockclickRepo)(nil).Series), ctx, linkID, q)
+}
+
+// Summary mocks base method.
+func (m *MockclickRepo) Summary(ctx context.Context, linkID int64, q model.StatsQuery) (model.ClickSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Summary", ctx, linkID, q)
+	ret0, _ := ret[0].(model.ClickSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Summary indicates an expected call of Summary.
+func (mr *MockclickRepoMockRecorder) Summary(ctx, linkID, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Summary", reflect.TypeOf((*MockclickRepo)(nil).Summary), ctx, linkID, q)
+}
+
+// TopReferrers mocks base method.
+func (m *MockclickRepo) TopReferrers(ctx context.Context, linkID int64, q model.StatsQuery, limit uint64) ([]model.StatsCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopReferrers", ctx, linkID, q, limit)
+	ret0, _ := ret[0].([]model.StatsCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopReferrers indicates an expected call of TopReferrers.
+func (mr *MockclickRepoMockRecorder) TopReferrers(ctx, linkID, q, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopReferrers", reflect.TypeOf((*MockclickRepo)(nil).TopReferrers), ctx, linkID, q, limit)
+}
+
+// TopUserAgents mocks base method.
+func (m *MockclickRepo) TopUserAgents(ctx context.Context, linkID int64, q model.StatsQuery, limit uint64) ([]model.StatsCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopUserAgents", ctx, linkID, q, limit)
+	ret0, _ := ret[0].([]model.StatsCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopUserAgents indicates an expected call of TopUserAgents.
+func (mr *MockclickRepoMockRecorder) TopUserAgents(ctx, linkID, q, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopUserAgents", reflect.TypeOf((*MockclickRepo)(nil).TopUserAgents), ctx, linkID, q, limit)
+}
@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+const topLimit = 10
+
+// Upper bounds keep the generated series to a few hundred buckets.
+var maxRange = map[string]time.Duration{
+	model.StatsIntervalHour: 31 * 24 * time.Hour,
+	model.StatsIntervalDay:  366 * 24 * time.Hour,
+}
+
+var defaultRange = map[string]time.Duration{
+	model.StatsIntervalHour: 24 * time.Hour,
+	model.StatsIntervalDay:  30 * 24 * time.Hour,
+}
+
+type Usecase struct {
+	link  linkRepo
+	click clickRepo
+}
+
+func New(l linkRepo, c clickRepo) *Usecase {
+	return &Usecase{link: l, click: c}
+}
+
+func (s *Usecase) Stats(ctx context.Context, ownerID int64, code string, q model.StatsQuery) (model.LinkStats, error) {
+	q, err := normalizeQuery(q, time.Now())
+	if err != nil {
+		return model.LinkStats{}, err
+	}
+
+	// Clicks are recorded under the link id, so codes and aliases are
+	// resolved to it first.
+	l, err := s.link.Get(ctx, code)
+	if err != nil {
+		return model.LinkStats{}, err
+	}
+	if l.OwnerID != ownerID {
+		return model.LinkStats{}, model.ErrForbidden
+	}
+
+	res := model.LinkStats{Code: l.Code, Query: q}
+
+	if res.ClickSummary, err = s.click.Summary(ctx, l.ID, q); err != nil {
+		return model.LinkStats{}, err
+	}
+	if res.Series, err = s.click.Series(ctx, l.ID, q); err != nil {
+		return model.LinkStats{}, err
+	}
+	if res.TopReferrers, err = s.click.TopReferrers(ctx, l.ID, q, topLimit); err != nil {
+		return model.LinkStats{}, err
+	}
+	if res.TopUserAgents, err = s.click.TopUserAgents(ctx, l.ID, q, topLimit); err != nil {
+		return model.LinkStats{}, err
+	}
+
+	return res, nil
+}
+
+func normalizeQuery(q model.StatsQuery, now time.Time) (model.StatsQuery, error) {
+	if q.Interval == "" {
+		q.Interval = model.StatsIntervalDay
+	}
+	limit, ok := maxRange[q.Interval]
+	if !ok {
+		return model.StatsQuery{}, model.NewValidationError(model.ErrInvalidInput, "interval", "must be hour or day")
+	}
+
+	if q.To.IsZero() {
+		q.To = now
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultRange[q.Interval])
+	}
+	if !q.From.Before(q.To) {
+		return model.StatsQuery{}, model.NewValidationError(model.ErrInvalidInput, "from", "must be before to")
+	}
+	if q.To.Sub(q.From) > limit {
+		return model.StatsQuery{}, model.NewValidationError(model.ErrInvalidInput, "from",
+			fmt.Sprintf("must be at most %d days before to", int(limit.Hours()/24)))
+	}
+
+	return q, nil
+}
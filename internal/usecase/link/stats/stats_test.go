@@ -0,0 +1,138 @@
+package stats_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/usecase/link/stats"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		links := NewMocklinkRepo(ctrl)
+		clicks := NewMockclickRepo(ctrl)
+		uc := stats.New(links, clicks)
+
+		q := model.StatsQuery{
+			From:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+			Interval: model.StatsIntervalDay,
+		}
+		first := q.From.Add(time.Hour)
+		summary := model.ClickSummary{TotalClicks: 3, UniqueVisitors: 2, FirstClick: &first, LastClick: &first}
+		series := []model.StatsBucket{{Start: q.From, Clicks: 3}, {Start: q.From.Add(24 * time.Hour)}}
+		referrers := []model.StatsCount{{Value: "https://news.example", Clicks: 3}}
+		agents := []model.StatsCount{{Value: "Firefox", Clicks: 3}}
+
+		links.EXPECT().
+			Get(gomock.Any(), "spring-sale").
+			Return(model.Link{ID: 42, Url: "https://test.com", Code: "Code123", Alias: "spring-sale", OwnerID: 7}, nil)
+		clicks.EXPECT().Summary(gomock.Any(), int64(42), q).Return(summary, nil)
+		clicks.EXPECT().Series(gomock.Any(), int64(42), q).Return(series, nil)
+		clicks.EXPECT().TopReferrers(gomock.Any(), int64(42), q, gomock.Any()).Return(referrers, nil)
+		clicks.EXPECT().TopUserAgents(gomock.Any(), int64(42), q, gomock.Any()).Return(agents, nil)
+
+		got, err := uc.Stats(ctx, 7, "spring-sale", q)
+		require.NoError(t, err)
+		require.Equal(t, model.LinkStats{
+			Code:          "Code123",
+			ClickSummary:  summary,
+			Query:         q,
+			Series:        series,
+			TopReferrers:  referrers,
+			TopUserAgents: agents,
+		}, got)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+		for _, q := range []model.StatsQuery{
+			{Interval: "minute"},
+			{From: now, To: now.Add(-time.Hour)},
+			{From: now.Add(-60 * 24 * time.Hour), To: now, Interval: model.StatsIntervalHour},
+		} {
+			ctrl := gomock.NewController(t)
+			uc := stats.New(NewMocklinkRepo(ctrl), NewMockclickRepo(ctrl))
+
+			got, err := uc.Stats(context.Background(), 7, "Code123", q)
+			require.ErrorIs(t, err, model.ErrInvalidInput)
+			require.Empty(t, got)
+		}
+	})
+
+	t.Run("link not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		links := NewMocklinkRepo(ctrl)
+		uc := stats.New(links, NewMockclickRepo(ctrl))
+
+		links.EXPECT().
+			Get(gomock.Any(), "Code123").
+			Return(model.Link{}, model.ErrCodeNotFound)
+
+		got, err := uc.Stats(context.Background(), 7, "Code123", model.StatsQuery{})
+		require.ErrorIs(t, err, model.ErrCodeNotFound)
+		require.Empty(t, got)
+	})
+
+	t.Run("not the owner", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		links := NewMocklinkRepo(ctrl)
+		uc := stats.New(links, NewMockclickRepo(ctrl))
+
+		links.EXPECT().
+			Get(gomock.Any(), "Code123").
+			Return(model.Link{Code: "Code123", OwnerID: 8}, nil)
+
+		got, err := uc.Stats(context.Background(), 7, "Code123", model.StatsQuery{})
+		require.ErrorIs(t, err, model.ErrForbidden)
+		require.Empty(t, got)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		links := NewMocklinkRepo(ctrl)
+		clicks := NewMockclickRepo(ctrl)
+		uc := stats.New(links, clicks)
+
+		wantErr := errors.New("repo failure")
+
+		links.EXPECT().
+			Get(gomock.Any(), "Code123").
+			Return(model.Link{ID: 42, Code: "Code123", OwnerID: 7}, nil)
+		clicks.EXPECT().
+			Summary(gomock.Any(), int64(42), gomock.Any()).
+			Return(model.ClickSummary{}, wantErr)
+
+		got, err := uc.Stats(context.Background(), 7, "Code123", model.StatsQuery{})
+		require.ErrorIs(t, err, wantErr)
+		require.Empty(t, got)
+	})
+}
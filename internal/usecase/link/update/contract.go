@@ -0,0 +1,24 @@
+//go:generate mockgen -source ${GOFILE} -package ${GOPACKAGE}_test -destination mocks_test.go
+package update
+
+import (
+	"context"
+
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+type linkRepo interface {
+	Get(ctx context.Context, code string) (model.Link, error)
+	Update(ctx context.Context, l model.Link) (model.Link, error)
+}
+
+type destinationPolicy interface {
+	Check(ctx context.Context, destination string) error
+}
+
+// blocklistStage labels the blocklist hits of this usecase.
+const blocklistStage = "update"
+
+type destinationBlocklist interface {
+	Blocked(ctx context.Context, stage, destination string) bool
+}
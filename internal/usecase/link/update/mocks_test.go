@@ -0,0 +1,148 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+//
+// Generated by this command:
+//
+//	mockgen -source contract.go -package update_test -destination mocks_test.go
+//
+
+// Package update_test is a generated GoMock package.
+package update_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/domovonok/url-shortener/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MocklinkRepo is a mock of linkRepo interface.
+type MocklinkRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MocklinkRepoMockRecorder
+	isgomock struct{}
+}
+
+// MocklinkRepoMockRecorder is the mock recorder for MocklinkRepo.
+type MocklinkRepoMockRecorder struct {
+	mock *MocklinkRepo
+}
+
+// NewMocklinkRepo creates a new mock instance.
+func NewMocklinkRepo(ctrl *gomock.Controller) *MocklinkRepo {
+	mock := &MocklinkRepo{ctrl: ctrl}
+	mock.recorder = &MocklinkRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocklinkRepo) EXPECT() *MocklinkRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MocklinkRepo) Get(ctx context.Context, code string) (model.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, code)
+	ret0, _ := ret[0].(model.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MocklinkRepoMockRecorder) Get(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MocklinkRepo)(nil).Get), ctx, code)
+}
+
+// Update mocks base method.
+func (m *MocklinkRepo) Update(ctx context.Context, l model.Link) (model.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, l)
+	ret0, _ := ret[0].(model.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MocklinkRepoMockRecorder) Update(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MocklinkRepo)(nil).Update), ctx, l)
+}
+
+// MockdestinationPolicy is a mock of destinationPolicy interface.
+type MockdestinationPolicy struct {
+	ctrl     *gomock.Controller
+	recorder *MockdestinationPolicyMockRecorder
+	isgomock struct{}
+}
+
+// MockdestinationPolicyMockRecorder is the mock recorder for MockdestinationPolicy.
+type MockdestinationPolicyMockRecorder struct {
+	mock *MockdestinationPolicy
+}
+
+// NewMockdestinationPolicy creates a new mock instance.
+func NewMockdestinationPolicy(ctrl *gomock.Controller) *MockdestinationPolicy {
+	mock := &MockdestinationPolicy{ctrl: ctrl}
+	mock.recorder = &MockdestinationPolicyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdestinationPolicy) EXPECT() *MockdestinationPolicyMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockdestinationPolicy) Check(ctx context.Context, destination string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, destination)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockdestinationPolicyMockRecorder) Check(ctx, destination any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockdestinationPolicy)(nil).Check), ctx, destination)
+}
+
+// MockdestinationBlocklist is a mock of destinationBlocklist interface.
+type MockdestinationBlocklist struct {
+	ctrl     *gomock.Controller
+	recorder *MockdestinationBlocklistMockRecorder
+	isgomock struct{}
+}
+
+// MockdestinationBlocklistMockRecorder is the mock recorder for MockdestinationBlocklist.
+type MockdestinationBlocklistMockRecorder struct {
+	mock *MockdestinationBlocklist
+}
+
+// NewMockdestinationBlocklist creates a new mock instance.
+func NewMockdestinationBlocklist(ctrl *gomock.Controller) *MockdestinationBlocklist {
+	mock := &MockdestinationBlocklist{ctrl: ctrl}
+	mock.recorder = &MockdestinationBlocklistMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdestinationBlocklist) EXPECT() *MockdestinationBlocklistMockRecorder {
+	return m.recorder
+}
+
+// Blocked mocks base method.
+func (m *MockdestinationBlocklist) Blocked(ctx context.Context, stage, destination string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Blocked", ctx, stage, destination)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Blocked indicates an expected call of Blocked.
+func (mr *MockdestinationBlocklistMockRecorder) Blocked(ctx, stage, destination any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Blocked", reflect.TypeOf((*MockdestinationBlocklist)(nil).Blocked), ctx, stage, destination)
+}
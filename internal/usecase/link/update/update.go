@@ -0,0 +1,42 @@
+package update
+
+import (
+	"context"
+
+	"github.com/domovonok/url-shortener/internal/destination"
+	"github.com/domovonok/url-shortener/internal/model"
+)
+
+type Usecase struct {
+	link      linkRepo
+	policy    destinationPolicy
+	blocklist destinationBlocklist
+}
+
+func New(l linkRepo, p destinationPolicy, b destinationBlocklist) *Usecase {
+	return &Usecase{link: l, policy: p, blocklist: b}
+}
+
+func (s *Usecase) Update(ctx context.Context, ownerID int64, code, url string) (model.Link, error) {
+	url, err := destination.Normalize(url)
+	if err != nil {
+		return model.Link{}, err
+	}
+	if s.blocklist.Blocked(ctx, blocklistStage, url) {
+		return model.Link{}, model.NewValidationError(model.ErrDestinationBlocked, "url", "is on the blocklist")
+	}
+	if err := s.policy.Check(ctx, url); err != nil {
+		return model.Link{}, err
+	}
+
+	l, err := s.link.Get(ctx, code)
+	if err != nil {
+		return model.Link{}, err
+	}
+	if l.OwnerID != ownerID {
+		return model.Link{}, model.ErrForbidden
+	}
+
+	l.Url = url
+	return s.link.Update(ctx, l)
+}
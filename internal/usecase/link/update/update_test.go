@@ -0,0 +1,126 @@
+package update_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/usecase/link/linktest"
+	"github.com/domovonok/url-shortener/internal/usecase/link/update"
+)
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	existing := model.Link{
+		Url:       "https://test.com/typo",
+		Code:      "Code123",
+		Alias:     "spring-sale",
+		CreatedAt: time.Unix(123, 0).UTC(),
+		OwnerID:   7,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		repo := NewMocklinkRepo(ctrl)
+		uc := update.New(repo, linktest.Policy{}, linktest.Blocklist{})
+
+		want := existing
+		want.Url = "https://test.com/fixed"
+
+		repo.EXPECT().
+			Get(gomock.Any(), "spring-sale").
+			Return(existing, nil)
+		repo.EXPECT().
+			Update(gomock.Any(), want).
+			Return(want, nil)
+
+		got, err := uc.Update(ctx, 7, "spring-sale", want.Url)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	})
+
+	t.Run("not the owner", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := NewMocklinkRepo(ctrl)
+		uc := update.New(repo, linktest.Policy{}, linktest.Blocklist{})
+
+		repo.EXPECT().
+			Get(gomock.Any(), "Code123").
+			Return(existing, nil)
+
+		got, err := uc.Update(context.Background(), 8, "Code123", "https://test.com/fixed")
+		require.ErrorIs(t, err, model.ErrForbidden)
+		require.Empty(t, got)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		t.Parallel()
+
+		for _, url := range []string{"", "javascript:alert(1)"} {
+			ctrl := gomock.NewController(t)
+			uc := update.New(NewMocklinkRepo(ctrl), linktest.Policy{}, linktest.Blocklist{})
+
+			got, err := uc.Update(context.Background(), 7, "Code123", url)
+			require.ErrorIs(t, err, model.ErrInvalidURL, url)
+			require.Empty(t, got)
+		}
+	})
+
+	t.Run("rejected destination", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name      string
+			policy    linktest.Policy
+			blocklist linktest.Blocklist
+			want      error
+		}{
+			{name: "denied", policy: linktest.Policy{Denied: "https://evil.example/"}, want: model.ErrDestinationDenied},
+			{name: "blocklisted", blocklist: linktest.Blocklist{Stage: "update", URL: "https://evil.example/"}, want: model.ErrDestinationBlocked},
+		}
+
+		for _, tt := range tests {
+			ctrl := gomock.NewController(t)
+			uc := update.New(NewMocklinkRepo(ctrl), tt.policy, tt.blocklist)
+
+			got, err := uc.Update(context.Background(), 7, "Code123", "https://EVIL.example")
+			require.ErrorIs(t, err, tt.want, tt.name)
+			require.Empty(t, got)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := NewMocklinkRepo(ctrl)
+		uc := update.New(repo, linktest.Policy{}, linktest.Blocklist{})
+
+		wantErr := errors.New("repo failure")
+
+		repo.EXPECT().
+			Get(gomock.Any(), "Code123").
+			Return(model.Link{}, wantErr)
+
+		got, err := uc.Update(context.Background(), 7, "Code123", "https://test.com/fixed")
+		require.ErrorIs(t, err, wantErr)
+		require.Empty(t, got)
+	})
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS clicks (
+    id          BIGSERIAL PRIMARY KEY,
+    link_id     BIGINT NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    clicked_at  TIMESTAMPTZ NOT NULL,
+    referrer    TEXT NOT NULL DEFAULT '',
+    user_agent  TEXT NOT NULL DEFAULT '',
+    ip          INET
+);
+
+CREATE INDEX IF NOT EXISTS clicks_link_id_clicked_at_idx ON clicks (link_id, clicked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS clicks;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_keys (
+    id          BIGSERIAL PRIMARY KEY,
+    owner_id    BIGINT NOT NULL,
+    key_hash    BYTEA NOT NULL UNIQUE,
+    name        TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ DEFAULT NOW(),
+    revoked_at  TIMESTAMPTZ
+);
+
+ALTER TABLE links ADD COLUMN IF NOT EXISTS owner_id BIGINT;
+
+-- Links are deduplicated per owner, so one owner editing a link never
+-- changes where another owner's link points.
+ALTER TABLE links DROP CONSTRAINT IF EXISTS links_url_key;
+ALTER TABLE links ADD CONSTRAINT links_url_owner_id_key UNIQUE NULLS NOT DISTINCT (url, owner_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE links DROP CONSTRAINT IF EXISTS links_url_owner_id_key;
+ALTER TABLE links ADD CONSTRAINT links_url_key UNIQUE (url);
+ALTER TABLE links DROP COLUMN IF EXISTS owner_id;
+
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
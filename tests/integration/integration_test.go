@@ -4,22 +4,34 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/domovonok/url-shortener/internal/auth"
+	"github.com/domovonok/url-shortener/internal/blocklist"
+	"github.com/domovonok/url-shortener/internal/config"
+	"github.com/domovonok/url-shortener/internal/destination"
 	"github.com/domovonok/url-shortener/internal/logger"
-	"github.com/domovonok/url-shortener/internal/model"
+	"github.com/domovonok/url-shortener/internal/metrics"
+	clickRepo "github.com/domovonok/url-shortener/internal/repo/click"
 	linkRepo "github.com/domovonok/url-shortener/internal/repo/link"
+	"github.com/domovonok/url-shortener/internal/repo/link/codec"
+	"github.com/domovonok/url-shortener/internal/tracker"
 	"github.com/domovonok/url-shortener/internal/transport/http/dto/link"
 	linkHandler "github.com/domovonok/url-shortener/internal/transport/http/link"
 	linkCreateUsecase "github.com/domovonok/url-shortener/internal/usecase/link/create"
 	linkGetUsecase "github.com/domovonok/url-shortener/internal/usecase/link/get"
+	linkDeleteUsecase "github.com/domovonok/url-shortener/internal/usecase/link/remove"
+	linkStatsUsecase "github.com/domovonok/url-shortener/internal/usecase/link/stats"
+	linkUpdateUsecase "github.com/domovonok/url-shortener/internal/usecase/link/update"
 )
 
 func TestLinkController_Integration(t *testing.T) {
@@ -36,10 +48,30 @@ func TestLinkController_Integration(t *testing.T) {
 	})
 
 	l := logger.MustInit(true)
-	repo := linkRepo.New(pool)
-	createUC := linkCreateUsecase.New(repo)
-	getUC := linkGetUsecase.New(repo)
-	controller := linkHandler.New(createUC, getUC, l)
+	prom := metrics.NewPrometheusMetrics()
+	repo := linkRepo.New(pool, codec.Base62{})
+	policy, err := destination.NewPolicy(config.DestinationConfig{}, "https://sho.rt", nil)
+	require.NoError(t, err)
+	bl, err := blocklist.New(config.BlocklistConfig{}, l, prom)
+	require.NoError(t, err)
+	createUC := linkCreateUsecase.New(repo, policy, bl)
+	getUC := linkGetUsecase.New(repo, bl)
+	clicks := clickRepo.New(pool)
+	clickTracker := tracker.New(clicks, config.ClicksConfig{
+		BufferSize:    100,
+		BatchSize:     10,
+		FlushInterval: 100 * time.Millisecond,
+		WriteTimeout:  time.Second,
+	}, prom, l)
+	go clickTracker.Run()
+	t.Cleanup(func() {
+		_ = clickTracker.Close(ctx)
+	})
+
+	statsUC := linkStatsUsecase.New(repo, clicks)
+	updateUC := linkUpdateUsecase.New(repo, policy, bl)
+	deleteUC := linkDeleteUsecase.New(repo)
+	controller := linkHandler.New(createUC, getUC, updateUC, deleteUC, statsUC, clickTracker, "https://sho.rt", l)
 
 	t.Run("Successfully create and get link", func(t *testing.T) {
 		originalURL := "https://test.com/qwerty123_-"
@@ -50,22 +82,34 @@ func TestLinkController_Integration(t *testing.T) {
 		jsonData, err := json.Marshal(reqBody)
 		require.NoError(t, err)
 
-		req := httptest.NewRequest("POST", "/", bytes.NewBuffer(jsonData))
+		req := newOwnedRequest("POST", "/", bytes.NewBuffer(jsonData))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 
 		controller.Create(w, req)
 
-		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, http.StatusCreated, w.Code)
 
-		var createdLink model.Link
+		var createdLink link.CreateResponse
 		err = json.Unmarshal(w.Body.Bytes(), &createdLink)
 		require.NoError(t, err)
 
 		assert.Equal(t, originalURL, createdLink.Url)
 		assert.NotEmpty(t, createdLink.Code)
+		assert.Equal(t, "https://sho.rt/"+createdLink.Code, createdLink.ShortUrl)
+		assert.Equal(t, createdLink.ShortUrl, w.Header().Get("Location"))
+		assert.False(t, createdLink.Existing)
 		assert.False(t, createdLink.CreatedAt.IsZero())
 
+		wAgain := httptest.NewRecorder()
+		controller.Create(wAgain, newOwnedRequest("POST", "/", bytes.NewBuffer(jsonData)))
+		assert.Equal(t, http.StatusOK, wAgain.Code)
+
+		var existingLink link.CreateResponse
+		require.NoError(t, json.Unmarshal(wAgain.Body.Bytes(), &existingLink))
+		assert.True(t, existingLink.Existing)
+		assert.Equal(t, createdLink.Code, existingLink.Code)
+
 		r := chi.NewRouter()
 		r.Get("/{code}", controller.Get)
 
@@ -74,8 +118,141 @@ func TestLinkController_Integration(t *testing.T) {
 
 		r.ServeHTTP(wGet, reqGet)
 
-		assert.Equal(t, http.StatusMovedPermanently, wGet.Code)
+		assert.Equal(t, http.StatusFound, wGet.Code)
 		assert.Equal(t, originalURL, wGet.Header().Get("Location"))
+		assert.Equal(t, "private, max-age=0", wGet.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Create with alias and get by alias", func(t *testing.T) {
+		originalURL := "https://test.com/spring-sale"
+		jsonData, err := json.Marshal(link.CreateRequest{Url: originalURL, Alias: "spring-sale"})
+		require.NoError(t, err)
+
+		req := newOwnedRequest("POST", "/", bytes.NewBuffer(jsonData))
+		w := httptest.NewRecorder()
+
+		controller.Create(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var createdLink link.CreateResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdLink))
+		assert.Equal(t, "spring-sale", createdLink.Alias)
+		assert.Equal(t, "https://sho.rt/spring-sale", createdLink.ShortUrl)
+
+		r := chi.NewRouter()
+		r.Get("/{code}", controller.Get)
+
+		for _, code := range []string{createdLink.Code, createdLink.Alias} {
+			wGet := httptest.NewRecorder()
+			r.ServeHTTP(wGet, httptest.NewRequest("GET", "/"+code, nil))
+
+			assert.Equal(t, http.StatusFound, wGet.Code)
+			assert.Equal(t, originalURL, wGet.Header().Get("Location"))
+		}
+
+		jsonData, err = json.Marshal(link.CreateRequest{Url: "https://test.com/other", Alias: "spring-sale"})
+		require.NoError(t, err)
+
+		w = httptest.NewRecorder()
+		controller.Create(w, newOwnedRequest("POST", "/", bytes.NewBuffer(jsonData)))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		// "sale" is also a valid base62 code, of some past or future link.
+		jsonData, err = json.Marshal(link.CreateRequest{Url: "https://test.com/sale", Alias: "sale"})
+		require.NoError(t, err)
+
+		w = httptest.NewRecorder()
+		controller.Create(w, newOwnedRequest("POST", "/", bytes.NewBuffer(jsonData)))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "urn:url-shortener:problem:invalid-alias")
+	})
+
+	t.Run("Clicks show up in stats", func(t *testing.T) {
+		jsonData, err := json.Marshal(link.CreateRequest{Url: "https://test.com/stats"})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		controller.Create(w, newOwnedRequest("POST", "/", bytes.NewBuffer(jsonData)))
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var createdLink link.CreateResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdLink))
+
+		r := chi.NewRouter()
+		r.Get("/{code}", controller.Get)
+		r.Get("/api/links/{code}/stats", controller.Stats)
+
+		for range 2 {
+			reqGet := httptest.NewRequest("GET", "/"+createdLink.Code, nil)
+			reqGet.Header.Set("Referer", "https://news.example")
+			r.ServeHTTP(httptest.NewRecorder(), reqGet)
+		}
+
+		require.Eventually(t, func() bool {
+			wStats := httptest.NewRecorder()
+			r.ServeHTTP(wStats, newOwnedRequest("GET", "/api/links/"+createdLink.Code+"/stats?interval=hour", nil))
+			if wStats.Code != http.StatusOK {
+				return false
+			}
+
+			var stats link.StatsResponse
+			require.NoError(t, json.Unmarshal(wStats.Body.Bytes(), &stats))
+			return stats.TotalClicks == 2 &&
+				len(stats.TopReferrers) == 1 &&
+				stats.TopReferrers[0].Referrer == "https://news.example"
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("Update and delete link", func(t *testing.T) {
+		jsonData, err := json.Marshal(link.CreateRequest{Url: "https://test.com/typo"})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		controller.Create(w, newOwnedRequest("POST", "/", bytes.NewBuffer(jsonData)))
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var createdLink link.CreateResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdLink))
+
+		r := chi.NewRouter()
+		r.Get("/{code}", controller.Get)
+		r.Patch("/api/links/{code}", controller.Update)
+		r.Delete("/api/links/{code}", controller.Delete)
+
+		jsonData, err = json.Marshal(link.UpdateRequest{Url: "https://test.com/fixed"})
+		require.NoError(t, err)
+
+		wUpdate := httptest.NewRecorder()
+		r.ServeHTTP(wUpdate, newOwnedRequest("PATCH", "/api/links/"+createdLink.Code, bytes.NewBuffer(jsonData)))
+		require.Equal(t, http.StatusOK, wUpdate.Code)
+
+		wGet := httptest.NewRecorder()
+		r.ServeHTTP(wGet, httptest.NewRequest("GET", "/"+createdLink.Code, nil))
+		assert.Equal(t, "https://test.com/fixed", wGet.Header().Get("Location"))
+
+		wDelete := httptest.NewRecorder()
+		r.ServeHTTP(wDelete, newOwnedRequest("DELETE", "/api/links/"+createdLink.Code, nil))
+		require.Equal(t, http.StatusNoContent, wDelete.Code)
+
+		wGet = httptest.NewRecorder()
+		r.ServeHTTP(wGet, httptest.NewRequest("GET", "/"+createdLink.Code, nil))
+		assert.Equal(t, http.StatusNotFound, wGet.Code)
+
+		// Shortening the URL again revives the deleted link as a new one.
+		jsonData, err = json.Marshal(link.CreateRequest{Url: "https://test.com/fixed"})
+		require.NoError(t, err)
+
+		wAgain := httptest.NewRecorder()
+		controller.Create(wAgain, newOwnedRequest("POST", "/", bytes.NewBuffer(jsonData)))
+		require.Equal(t, http.StatusCreated, wAgain.Code)
+
+		var revivedLink link.CreateResponse
+		require.NoError(t, json.Unmarshal(wAgain.Body.Bytes(), &revivedLink))
+		assert.False(t, revivedLink.Existing)
+		assert.Equal(t, createdLink.Code, revivedLink.Code)
 	})
 
 	t.Run("Get non-existent link returns error", func(t *testing.T) {
@@ -87,8 +264,9 @@ func TestLinkController_Integration(t *testing.T) {
 
 		r.ServeHTTP(wGet, reqGet)
 
-		assert.Equal(t, http.StatusBadRequest, wGet.Code)
-		assert.Contains(t, wGet.Body.String(), "Code Not Found")
+		assert.Equal(t, http.StatusNotFound, wGet.Code)
+		assert.Equal(t, "application/problem+json", wGet.Header().Get("Content-Type"))
+		assert.Contains(t, wGet.Body.String(), "urn:url-shortener:problem:not-found")
 	})
 
 	t.Run("Create with invalid input returns error", func(t *testing.T) {
@@ -99,6 +277,13 @@ func TestLinkController_Integration(t *testing.T) {
 		controller.Create(w, req)
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
-		assert.Contains(t, w.Body.String(), "Invalid Input")
+		assert.Contains(t, w.Body.String(), "Malformed Request")
 	})
 }
+
+const testOwnerID = 1
+
+func newOwnedRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	return req.WithContext(auth.WithOwner(req.Context(), testOwnerID))
+}